@@ -1,10 +1,41 @@
 package main
 
 import (
-    "organization_management/pkg"
+	"fmt"
+	"os"
+
+	"organization_management/pkg"
 )
 
+const usage = `Usage:
+  server                                                 start the API (default)
+  export-organization -id <organization_id> [-out file]  write an organization archive
+  import-organization -in <file> [-on-conflict mode]     restore an organization archive (mode: fail, skip, rename)
+`
+
 func main() {
-    // Start the application
-    pkg.StartApplication()
-}
\ No newline at end of file
+	command := "server"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	var err error
+	switch command {
+	case "server":
+		// Start the application
+		err = pkg.StartApplication()
+	case "export-organization":
+		err = exportOrganization(os.Args[2:])
+	case "import-organization":
+		err = importOrganization(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"organization_management/pkg"
+	"organization_management/pkg/archive"
+	util "organization_management/pkg/utils"
+)
+
+// exportOrganization writes the archive of an organization to a file, or to stdout.
+func exportOrganization(args []string) error {
+	flags := flag.NewFlagSet("export-organization", flag.ContinueOnError)
+	orgID := flags.String("id", "", "organization id to export")
+	out := flags.String("out", "", "file to write the archive to (default stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *orgID == "" {
+		return errors.New("-id is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	app, err := pkg.OpenApp(ctx)
+	if err != nil {
+		return err
+	}
+	defer app.Close(context.Background())
+
+	exported, err := archive.Export(ctx, app.Store, *orgID)
+	if err != nil {
+		return err
+	}
+
+	var writer io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		writer = file
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(exported)
+}
+
+// importOrganization restores an organization from an archive file, or from stdin, and prints the result.
+func importOrganization(args []string) error {
+	flags := flag.NewFlagSet("import-organization", flag.ContinueOnError)
+	in := flags.String("in", "", "archive file to import (default stdin)")
+	onConflict := flags.String("on-conflict", util.ArchiveConflictFail, "when the organization name exists: fail, skip or rename")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !util.ValidArchiveConflict(*onConflict) {
+		return util.ErrInvalidArchiveConflict
+	}
+
+	var reader io.Reader = os.Stdin
+	if *in != "" {
+		file, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+	}
+	archived, err := util.DecodeOrganizationArchive(reader)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	app, err := pkg.OpenApp(ctx)
+	if err != nil {
+		return err
+	}
+	defer app.Close(context.Background())
+
+	result, err := archive.Import(ctx, app.Store, archived, *onConflict)
+	if err != nil {
+		return fmt.Errorf("importing organization: %w", err)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	repository "organization_management/pkg/database/mongodb/repository"
+	util "organization_management/pkg/utils"
+)
+
+// AdminOnlyMiddleware lets the request through only for platform administrators.
+// API keys are never accepted here. It must run after JwtAuthMiddleware.
+func AdminOnlyMiddleware(store *repository.Store, admins util.AdminSettings) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := util.ExtractClaims(c)
+		if err != nil || claims.TokenType != util.TokenTypeAccess || claims.IsImpersonated() {
+			forbidAdminAccess(c)
+			return
+		}
+		if requirePlatformAdmin(c, store, admins, claims) {
+			c.Next()
+		}
+	}
+}
+
+// AdminScopeMiddleware lets the request through for platform administrators, either with an access token
+// or with one of their API keys that grants scope. It must run after JwtAuthMiddleware.
+func AdminScopeMiddleware(store *repository.Store, admins util.AdminSettings, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := util.ExtractClaims(c)
+		if err != nil || claims.IsImpersonated() ||
+			(claims.TokenType != util.TokenTypeAccess && claims.TokenType != util.TokenTypeAPIKey) || !claims.HasScope(scope) {
+			forbidAdminAccess(c)
+			return
+		}
+		if requirePlatformAdmin(c, store, admins, claims) {
+			c.Next()
+		}
+	}
+}
+
+// requirePlatformAdmin aborts the request unless the credential belongs to a platform administrator.
+func requirePlatformAdmin(c *gin.Context, store *repository.Store, admins util.AdminSettings, claims *util.TokenClaims) bool {
+	admin, err := isPlatformAdmin(c.Request.Context(), store, admins, claims.UserID(), claims.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check admin access"})
+		c.Abort()
+		return false
+	}
+	if !admin {
+		forbidAdminAccess(c)
+		return false
+	}
+	return true
+}
+
+func forbidAdminAccess(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+	c.Abort()
+}
+
+// isPlatformAdmin reports whether the user is a platform administrator: either an email listed in
+// admin.emails, which bootstraps the first administrators, or an active user holding the platform admin role.
+func isPlatformAdmin(parent context.Context, store *repository.Store, admins util.AdminSettings, userID, email string) (bool, error) {
+	if admins.IsAdminEmail(email) {
+		return true, nil
+	}
+
+	ctx, cancel := context.WithTimeout(parent, 5*time.Second)
+	defer cancel()
+
+	user, err := store.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return user != nil && user.IsActive() && user.IsPlatformAdmin(), nil
+}
@@ -1,20 +1,197 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+	repository "organization_management/pkg/database/mongodb/repository"
+	repository_token "organization_management/pkg/database/redis/repository"
 	util "organization_management/pkg/utils"
 )
 
-func JwtAuthMiddleware() gin.HandlerFunc {
+// JwtAuthMiddleware authenticates the request with an access token, a service account token or a
+// personal API key and stores the resulting claims in the context. Tokens are verified with tokens and
+// checked against the users and keys in store and the revocations in redisClient; admins names the
+// administrators allowed to impersonate.
+func JwtAuthMiddleware(store *repository.Store, redisClient *goredis.Client, tokens *util.TokenVerifier, admins util.AdminSettings) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		err := util.TokenValid(c)
+		claims, err := authenticate(c, store, redisClient, tokens, admins)
 		if err != nil {
 			c.String(http.StatusUnauthorized, "Unauthorized")
 			c.Abort()
 			return
 		}
+		c.Set(util.ClaimsContextKey, claims)
 		c.Next()
+
+		if claims.IsImpersonated() {
+			auditImpersonatedRequest(c, store, claims)
+		}
+	}
+}
+
+// OptionalJwtAuthMiddleware authenticates the request like JwtAuthMiddleware when it carries a credential,
+// and lets it through without claims when it carries none or the credential is rejected.
+func OptionalJwtAuthMiddleware(store *repository.Store, redisClient *goredis.Client, tokens *util.TokenVerifier, admins util.AdminSettings) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if util.ExtractToken(c) == "" {
+			c.Next()
+			return
+		}
+		claims, err := authenticate(c, store, redisClient, tokens, admins)
+		if err != nil {
+			c.Next()
+			return
+		}
+		c.Set(util.ClaimsContextKey, claims)
+		c.Next()
+
+		if claims.IsImpersonated() {
+			auditImpersonatedRequest(c, store, claims)
+		}
+	}
+}
+
+// authenticate verifies the request's credential and checks that it was not revoked and that the user,
+// service account or impersonating administrator behind it is still allowed in.
+func authenticate(c *gin.Context, store *repository.Store, redisClient *goredis.Client, tokens *util.TokenVerifier, admins util.AdminSettings) (*util.TokenClaims, error) {
+	tokenString := util.ExtractToken(c)
+
+	if keyID, ok := util.ParseAPIKey(tokenString); ok {
+		claims, err := authenticateAPIKey(c.Request.Context(), store, keyID, tokenString)
+		if err == nil {
+			err = checkUserActive(c.Request.Context(), store, claims.UserID())
+		}
+		return claims, err
+	}
+
+	claims, err := tokens.VerifyBearerToken(tokenString)
+	if err == nil && claims.TokenType == util.TokenTypeServiceAccount {
+		err = checkServiceAccountEnabled(c.Request.Context(), store, claims.UserID())
 	}
-}
\ No newline at end of file
+	if err == nil && claims.TokenType == util.TokenTypeAccess {
+		err = checkTokenNotRevoked(c.Request.Context(), redisClient, claims)
+	}
+	if err == nil && claims.TokenType == util.TokenTypeAccess {
+		err = checkUserActive(c.Request.Context(), store, claims.UserID())
+	}
+	if err == nil && claims.IsImpersonated() {
+		err = checkImpersonatorAllowed(c.Request.Context(), store, admins, claims.Actor)
+	}
+	return claims, err
+}
+
+// ClientAccessTokenMiddleware authenticates requests from OpenID Connect clients, which carry an access
+// token issued by the token endpoint and verified with tokens, and stores its claims in the context.
+func ClientAccessTokenMiddleware(store *repository.Store, redisClient *goredis.Client, tokens *util.TokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := tokens.VerifyClientAccessToken(util.ExtractToken(c))
+		if err == nil {
+			err = checkTokenNotRevoked(c.Request.Context(), redisClient, claims)
+		}
+		if err == nil {
+			err = checkUserActive(c.Request.Context(), store, claims.UserID())
+		}
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_token"})
+			c.Abort()
+			return
+		}
+		c.Set(util.ClaimsContextKey, claims)
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests whose credential doesn't grant scope, or that target an organization
+// the credential is not bound to. It must run after JwtAuthMiddleware.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := util.ExtractClaims(c)
+		if err != nil || !claims.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Missing required scope: " + scope})
+			c.Abort()
+			return
+		}
+		if orgID := c.Param("organization_id"); orgID != "" && !claims.CanAccessOrganization(orgID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Credential is not allowed to access this organization"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// checkTokenNotRevoked rejects access tokens issued before the user's tokens were revoked.
+func checkTokenNotRevoked(ctx context.Context, redisClient *goredis.Client, claims *util.TokenClaims) error {
+	tokenRepo := repository_token.NewTokenRepository(redisClient)
+	revoked, err := tokenRepo.IsTokenRevoked(ctx, claims.UserID(), claims.IssueTime())
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return util.ErrInvalidToken
+	}
+	return nil
+}
+
+// checkUserActive rejects credentials of users who were suspended or disabled, or no longer exist, even if
+// revoking their tokens failed.
+func checkUserActive(parent context.Context, store *repository.Store, userID string) error {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Second)
+	defer cancel()
+
+	user, err := store.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive() {
+		return util.ErrInvalidToken
+	}
+	return nil
+}
+
+// checkServiceAccountEnabled makes sure a service account token stops working as soon as its account is disabled.
+func checkServiceAccountEnabled(parent context.Context, store *repository.Store, clientID string) error {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Second)
+	defer cancel()
+
+	account, err := store.GetServiceAccountByClientID(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	if account == nil || account.Disabled {
+		return util.ErrInvalidToken
+	}
+	return nil
+}
+
+func authenticateAPIKey(parent context.Context, store *repository.Store, keyID, key string) (*util.TokenClaims, error) {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Second)
+	defer cancel()
+
+	apiKey, err := store.GetAPIKeyByKeyID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil || !util.APIKeyMatches(key, apiKey.HashedKey) || !apiKey.IsActive(time.Now()) {
+		return nil, util.ErrInvalidToken
+	}
+
+	// Failing to record the last use shouldn't fail the request
+	_ = store.TouchAPIKey(ctx, keyID)
+
+	return &util.TokenClaims{
+		Email:     apiKey.UserEmail,
+		TokenType: util.TokenTypeAPIKey,
+		Scopes:    apiKey.Scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   apiKey.UserId,
+			ExpiresAt: jwt.NewNumericDate(apiKey.ExpiresAt),
+		},
+	}, nil
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	model "organization_management/pkg/database/mongodb/models"
+	repository "organization_management/pkg/database/mongodb/repository"
+	util "organization_management/pkg/utils"
+)
+
+// ForbidImpersonation rejects requests made with an impersonation token, for actions only the account
+// owner may take. It must run after JwtAuthMiddleware.
+func ForbidImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := util.ExtractClaims(c)
+		if err == nil && claims.IsImpersonated() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This action isn't allowed while impersonating a user"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// checkImpersonatorAllowed makes an impersonation token stop working as soon as the administrator who
+// requested it loses the platform admin role or is disabled.
+func checkImpersonatorAllowed(ctx context.Context, store *repository.Store, admins util.AdminSettings, actor *util.TokenActor) error {
+	admin, err := isPlatformAdmin(ctx, store, admins, actor.Subject, actor.Email)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		return util.ErrInvalidToken
+	}
+	return nil
+}
+
+// auditImpersonatedRequest records a request made with an impersonation token once it has been handled.
+// Failing to record it is logged rather than changing the response, which has already been written.
+func auditImpersonatedRequest(c *gin.Context, store *repository.Store, claims *util.TokenClaims) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), 5*time.Second)
+	defer cancel()
+
+	event := model.AuditEvent{
+		EventId:    uuid.NewString(),
+		ActorId:    claims.Actor.Subject,
+		ActorEmail: claims.Actor.Email,
+		Action:     model.AuditImpersonatedRequest,
+		TargetType: model.AuditTargetUser,
+		TargetId:   claims.UserID(),
+		Details: map[string]interface{}{
+			"email":  claims.Email,
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+			"route":  c.FullPath(),
+			"status": c.Writer.Status(),
+		},
+		ClientIP:  c.ClientIP(),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := store.InsertAuditEvent(ctx, event); err != nil {
+		log.Printf("audit: recording impersonated %s %s for %s: %v", c.Request.Method, c.Request.URL.Path, claims.UserID(), err)
+	}
+}
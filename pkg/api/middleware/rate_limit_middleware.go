@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	goredis "github.com/go-redis/redis/v8"
+	repository_token "organization_management/pkg/database/redis/repository"
+	util "organization_management/pkg/utils"
+)
+
+// RateLimitMiddleware throttles a route group per client IP and, once JwtAuthMiddleware has run,
+// per user, counting requests in redisClient. The most restrictive window is reported in the RateLimit-* headers.
+func RateLimitMiddleware(redisClient *goredis.Client, policy util.RateLimitPolicy) gin.HandlerFunc {
+	limiter := repository_token.NewRateLimitRepository(redisClient)
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var results []*repository_token.RateLimitResult
+		if policy.PerIP.Requests > 0 {
+			key := policy.Name + ":ip:" + c.ClientIP()
+			result, err := limiter.Allow(ctx, key, policy.PerIP.Requests, policy.PerIP.Window)
+			if err != nil {
+				// Don't take the API down with the rate limiter
+				log.Printf("rate limit check failed: %v", err)
+				c.Next()
+				return
+			}
+			results = append(results, result)
+		}
+		if value, ok := c.Get(util.ClaimsContextKey); ok && policy.PerUser.Requests > 0 {
+			key := policy.Name + ":user:" + value.(*util.TokenClaims).UserID()
+			result, err := limiter.Allow(ctx, key, policy.PerUser.Requests, policy.PerUser.Window)
+			if err != nil {
+				log.Printf("rate limit check failed: %v", err)
+				c.Next()
+				return
+			}
+			results = append(results, result)
+		}
+		if len(results) == 0 {
+			c.Next()
+			return
+		}
+
+		tightest := results[0]
+		for _, result := range results[1:] {
+			if !result.Allowed || (tightest.Allowed && result.Remaining < tightest.Remaining) {
+				tightest = result
+			}
+		}
+
+		reset := ceilSeconds(tightest.Reset)
+		c.Header("RateLimit-Limit", strconv.FormatInt(tightest.Limit, 10))
+		c.Header("RateLimit-Remaining", strconv.FormatInt(tightest.Remaining, 10))
+		c.Header("RateLimit-Reset", strconv.Itoa(reset))
+
+		if !tightest.Allowed {
+			c.Header("Retry-After", strconv.Itoa(reset))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
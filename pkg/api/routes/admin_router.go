@@ -0,0 +1,22 @@
+package route
+
+import (
+	controller "organization_management/pkg/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func AdminRoutes(routerGroup *gin.RouterGroup, h *controller.Handler) {
+	routerGroup.GET("/users", h.AdminListUsers())
+	routerGroup.GET("/users/:user_id", h.AdminGetUser())
+	routerGroup.POST("/users/unlock", h.UnlockUserAccount())
+	routerGroup.POST("/users/:user_id/suspend", h.AdminSuspendUser())
+	routerGroup.POST("/users/:user_id/disable", h.AdminDisableUser())
+	routerGroup.POST("/users/:user_id/enable", h.AdminEnableUser())
+	routerGroup.POST("/users/:user_id/password-reset", h.AdminForcePasswordReset())
+	routerGroup.PUT("/users/:user_id/platform-role", h.AdminSetPlatformRole())
+	routerGroup.POST("/users/:user_id/impersonate", h.AdminImpersonateUser())
+	routerGroup.GET("/organizations", h.AdminListOrganizations())
+	routerGroup.POST("/organizations/:organization_id/transfer-ownership", h.AdminTransferOwnership())
+	routerGroup.POST("/organizations/import", h.ImportOrganization())
+	routerGroup.GET("/audit-events", h.ListAuditEvents())
+}
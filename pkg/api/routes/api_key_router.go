@@ -0,0 +1,16 @@
+package route
+
+import (
+	middleware "organization_management/pkg/api/middleware"
+	controller "organization_management/pkg/controllers"
+	util "organization_management/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func APIKeyRoutes(routerGroup *gin.RouterGroup, h *controller.Handler) {
+	manage := middleware.RequireScope(util.ScopeAPIKeysManage)
+	ownerOnly := middleware.ForbidImpersonation()
+	routerGroup.POST("/api-keys", manage, ownerOnly, h.CreateAPIKey())
+	routerGroup.GET("/api-keys", manage, h.ListAPIKeys())
+	routerGroup.DELETE("/api-keys/:key_id", manage, ownerOnly, h.RevokeAPIKey())
+}
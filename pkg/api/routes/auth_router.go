@@ -1,16 +1,25 @@
 package route
 
 import (
+	middleware "organization_management/pkg/api/middleware"
 	controller "organization_management/pkg/controllers"
 	"github.com/gin-gonic/gin"
 )
 
-func AuthRoutes(routerGroup *gin.RouterGroup) {
-	routerGroup.POST("/signup", controller.RegisterUser())
-	routerGroup.POST("/signin", controller.LoginUser())
-	routerGroup.POST("/refresh-token", controller.RefreshToken())
+func AuthRoutes(routerGroup *gin.RouterGroup, h *controller.Handler) {
+	routerGroup.POST("/signup", h.RegisterUser())
+	routerGroup.POST("/signin", h.LoginUser())
+	routerGroup.POST("/refresh-token", h.RefreshToken())
+	routerGroup.POST("/password/reset", h.ResetPassword())
+	routerGroup.POST("/service-accounts/token", h.IssueServiceAccountToken())
+	routerGroup.GET("/auth/:provider/login", h.FederatedLogin())
+	routerGroup.GET("/auth/:provider/callback", h.FederatedCallback())
 }
 
-func ProtectedUderRoutes(routerGroup *gin.RouterGroup) {
-	routerGroup.POST("/revoke-refresh-token/", controller.RevokeToken())
+func ProtectedUderRoutes(routerGroup *gin.RouterGroup, h *controller.Handler) {
+	routerGroup.POST("/revoke-refresh-token/", h.RevokeToken())
+	ownerOnly := middleware.ForbidImpersonation()
+	routerGroup.GET("/me/export", ownerOnly, h.ExportMyData())
+	routerGroup.DELETE("/me", ownerOnly, h.DeleteMyAccount())
+	routerGroup.PUT("/me/password", ownerOnly, h.ChangeMyPassword())
 }
\ No newline at end of file
@@ -0,0 +1,13 @@
+package route
+
+import (
+	controller "organization_management/pkg/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthRoutes registers the probes used by the orchestrator. They take no authentication and no rate limit.
+func HealthRoutes(routerGroup *gin.RouterGroup, accepting func() bool, checks ...controller.DependencyCheck) {
+	routerGroup.GET("/healthz", controller.Liveness())
+	routerGroup.GET("/readyz", controller.Readiness(accepting, checks...))
+}
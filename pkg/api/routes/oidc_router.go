@@ -0,0 +1,23 @@
+package route
+
+import (
+	middleware "organization_management/pkg/api/middleware"
+	controller "organization_management/pkg/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func OIDCRoutes(routerGroup *gin.RouterGroup, h *controller.Handler) {
+	routerGroup.GET("/.well-known/openid-configuration", h.OIDCDiscovery())
+	routerGroup.GET("/oauth2/jwks", h.OIDCKeys())
+	routerGroup.GET("/oauth2/authorize", middleware.OptionalJwtAuthMiddleware(h.Store, h.Redis, h.Tokens, h.Admins), h.OIDCAuthorize())
+	routerGroup.POST("/oauth2/authorize", h.OIDCAuthorizeSubmit())
+	routerGroup.POST("/oauth2/token", h.OIDCToken())
+	routerGroup.GET("/oauth2/userinfo", middleware.ClientAccessTokenMiddleware(h.Store, h.Redis, h.Tokens), h.OIDCUserInfo())
+	routerGroup.POST("/oauth2/userinfo", middleware.ClientAccessTokenMiddleware(h.Store, h.Redis, h.Tokens), h.OIDCUserInfo())
+}
+
+func OIDCClientAdminRoutes(routerGroup *gin.RouterGroup, h *controller.Handler) {
+	routerGroup.POST("/oidc/clients", h.RegisterOIDCClient())
+	routerGroup.GET("/oidc/clients", h.ListOIDCClients())
+	routerGroup.DELETE("/oidc/clients/:client_id", h.DeleteOIDCClient())
+}
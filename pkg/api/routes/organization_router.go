@@ -1,15 +1,41 @@
 package route
 
 import (
+	middleware "organization_management/pkg/api/middleware"
 	controller "organization_management/pkg/controllers"
+	util "organization_management/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
-func OrganizationRoutes(routerGroup *gin.RouterGroup) {
-	routerGroup.POST("/organization", controller.CreateOrganization())
-	routerGroup.GET("/organization/:organization_id", controller.ReadOrganization())
-	routerGroup.GET("/organization", controller.ReadAllOrganizations())
-	routerGroup.PUT("/organization/:organization_id", controller.UpdateOrganization())
-	routerGroup.DELETE("/organization/:organization_id", controller.DeleteOrganization())
-	routerGroup.POST("/organization/:organization_id/invite", controller.InviteUserToOrganization())
-}
\ No newline at end of file
+func OrganizationRoutes(routerGroup *gin.RouterGroup, h *controller.Handler) {
+	read := middleware.RequireScope(util.ScopeOrganizationsRead)
+	write := middleware.RequireScope(util.ScopeOrganizationsWrite)
+	ownerOnly := middleware.ForbidImpersonation()
+
+	routerGroup.POST("/organization", write, h.CreateOrganization())
+	routerGroup.GET("/organization/:organization_id", read, h.ReadOrganization())
+	routerGroup.GET("/organization", read, h.ReadAllOrganizations())
+	routerGroup.GET("/organization/discoverable", read, h.ListDiscoverableOrganizations())
+	routerGroup.PUT("/organization/:organization_id", write, h.UpdateOrganization())
+	routerGroup.DELETE("/organization/:organization_id", write, ownerOnly, h.DeleteOrganization())
+	routerGroup.POST("/organization/:organization_id/invite", write, h.InviteUserToOrganization())
+	routerGroup.POST("/organization/:organization_id/members/import", write, h.ImportOrganizationMembers())
+	routerGroup.GET("/organization/:organization_id/members/export", read, h.ExportOrganizationMembers())
+	routerGroup.GET("/organization/:organization_id/export", read, h.ExportOrganization())
+
+	routerGroup.POST("/organization/:organization_id/domains", write, h.ClaimOrganizationDomain())
+	routerGroup.GET("/organization/:organization_id/domains", read, h.ListOrganizationDomains())
+	routerGroup.POST("/organization/:organization_id/domains/:domain/verify", write, h.VerifyOrganizationDomain())
+	routerGroup.PATCH("/organization/:organization_id/domains/:domain", write, h.UpdateOrganizationDomain())
+	routerGroup.DELETE("/organization/:organization_id/domains/:domain", write, h.DeleteOrganizationDomain())
+
+	routerGroup.POST("/organization/:organization_id/join-requests", write, h.CreateJoinRequest())
+	routerGroup.GET("/organization/:organization_id/join-requests", read, h.ListJoinRequests())
+	routerGroup.POST("/organization/:organization_id/join-requests/:request_id/approve", write, h.ApproveJoinRequest())
+	routerGroup.POST("/organization/:organization_id/join-requests/:request_id/reject", write, h.RejectJoinRequest())
+
+	routerGroup.POST("/organization/:organization_id/invite-links", write, h.CreateInviteLink())
+	routerGroup.GET("/organization/:organization_id/invite-links", read, h.ListInviteLinks())
+	routerGroup.DELETE("/organization/:organization_id/invite-links/:link_id", write, h.RevokeInviteLink())
+	routerGroup.POST("/invite-links/redeem", write, h.RedeemInviteLink())
+}
@@ -0,0 +1,22 @@
+package route
+
+import (
+	controller "organization_management/pkg/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+func SCIMRoutes(routerGroup *gin.RouterGroup, h *controller.Handler) {
+	routerGroup.GET("/Users", h.SCIMListUsers())
+	routerGroup.POST("/Users", h.SCIMCreateUser())
+	routerGroup.GET("/Users/:id", h.SCIMGetUser())
+	routerGroup.PUT("/Users/:id", h.SCIMReplaceUser())
+	routerGroup.PATCH("/Users/:id", h.SCIMPatchUser())
+	routerGroup.DELETE("/Users/:id", h.SCIMDeleteUser())
+
+	routerGroup.GET("/Groups", h.SCIMListGroups())
+	routerGroup.POST("/Groups", h.SCIMCreateGroup())
+	routerGroup.GET("/Groups/:id", h.SCIMGetGroup())
+	routerGroup.PUT("/Groups/:id", h.SCIMReplaceGroup())
+	routerGroup.PATCH("/Groups/:id", h.SCIMPatchGroup())
+	routerGroup.DELETE("/Groups/:id", h.SCIMDeleteGroup())
+}
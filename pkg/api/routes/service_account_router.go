@@ -0,0 +1,18 @@
+package route
+
+import (
+	middleware "organization_management/pkg/api/middleware"
+	controller "organization_management/pkg/controllers"
+	util "organization_management/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func ServiceAccountRoutes(routerGroup *gin.RouterGroup, h *controller.Handler) {
+	write := middleware.RequireScope(util.ScopeOrganizationsWrite)
+	ownerOnly := middleware.ForbidImpersonation()
+
+	routerGroup.POST("/organization/:organization_id/service-accounts", write, ownerOnly, h.CreateServiceAccount())
+	routerGroup.GET("/organization/:organization_id/service-accounts", write, h.ListServiceAccounts())
+	routerGroup.PUT("/organization/:organization_id/service-accounts/:client_id", write, h.UpdateServiceAccount())
+	routerGroup.DELETE("/organization/:organization_id/service-accounts/:client_id", write, h.DeleteServiceAccount())
+}
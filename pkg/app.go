@@ -1,38 +1,385 @@
 package pkg
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
 	middleware "organization_management/pkg/api/middleware"
 	route "organization_management/pkg/api/routes"
+	"organization_management/pkg/config"
+	controller "organization_management/pkg/controllers"
 	database "organization_management/pkg/database/mongodb"
-	util "organization_management/pkg/utils"
+	repository "organization_management/pkg/database/mongodb/repository"
 	redis "organization_management/pkg/database/redis"
+	"organization_management/pkg/metrics"
+	"organization_management/pkg/tracing"
+	util "organization_management/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	goredis "github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func StartApplication() {
-	port := util.EnvPort()
+// App holds the clients and settings shared by the HTTP server and the command-line tools.
+// Nothing connects at import time; NewApp builds everything explicitly.
+type App struct {
+	Config *config.Config
+	Mongo  *mongo.Client
+	Redis  *goredis.Client
+	// Store reads and writes the collections of the configured database.
+	Store *repository.Store
+
+	// Features are the token verifier, password handling, policies and providers built from Config.
+	Features controller.Features
+
+	// accepting is true while the server takes new requests; /readyz reports it.
+	accepting atomic.Bool
+	// shutdownTracing flushes the spans still buffered for the exporter.
+	shutdownTracing func(context.Context) error
+}
+
+// NewApp builds the token verifier, password handling and the other features from cfg, connects to
+// MongoDB and Redis, and opens the store on the configured database and creates its indexes. Connection
+// failures are returned rather than ending the process.
+func NewApp(ctx context.Context, cfg *config.Config) (*App, error) {
+	features, err := newFeatures(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return nil, fmt.Errorf("setting up tracing: %w", err)
+	}
+	mongoClient, err := database.ConnectDB(ctx, cfg.Mongo)
+	if err != nil {
+		shutdownTracing(context.Background())
+		return nil, err
+	}
+	redisClient, err := redis.InitRedis(ctx, cfg.Redis)
+	if err != nil {
+		mongoClient.Disconnect(context.Background())
+		shutdownTracing(context.Background())
+		return nil, err
+	}
+	store := repository.NewStore(mongoClient.Database(cfg.Mongo.Database))
+	if err := store.EnsureIndexes(ctx); err != nil {
+		redisClient.Close()
+		mongoClient.Disconnect(context.Background())
+		shutdownTracing(context.Background())
+		return nil, fmt.Errorf("creating MongoDB indexes: %w", err)
+	}
+
+	return &App{
+		Config:          cfg,
+		Mongo:           mongoClient,
+		Redis:           redisClient,
+		Store:           store,
+		Features:        features,
+		shutdownTracing: shutdownTracing,
+	}, nil
+}
+
+// OpenApp loads the configuration and connects, for callers that don't need a custom config.
+func OpenApp(ctx context.Context) (*App, error) {
+	cfg, err := config.LoadDefault()
+	if err != nil {
+		return nil, err
+	}
+	return NewApp(ctx, cfg)
+}
+
+// Close disconnects from MongoDB and Redis and flushes the remaining spans, if tracing was set up.
+func (a *App) Close(ctx context.Context) error {
+	var errs []error
+	if err := a.Mongo.Disconnect(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("disconnecting from MongoDB: %w", err))
+	}
+	if err := a.Redis.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("closing Redis: %w", err))
+	}
+	if a.shutdownTracing != nil {
+		if err := a.shutdownTracing(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("flushing traces: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Serve listens on the configured ports until ctx is cancelled, then shuts down gracefully:
+// it stops accepting connections, lets in-flight requests finish and only then disconnects from
+// MongoDB and Redis, all within the configured shutdown timeout.
+//
+// Readiness turns false as soon as shutdown starts, and the listener stays open for the configured
+// delay so the orchestrator notices and stops routing traffic before connections are refused.
+func (a *App) Serve(ctx context.Context) error {
+	servers := []*http.Server{a.newServer(), a.newMetricsServer()}
+	listeners, err := listen(servers)
+	if err != nil {
+		closeCtx, cancel := context.WithTimeout(context.Background(), seconds(a.Config.Server.ShutdownTimeoutSeconds))
+		defer cancel()
+		return errors.Join(err, a.Close(closeCtx))
+	}
+	served := make(chan error, len(servers))
+	for i, server := range servers {
+		go func(server *http.Server, listener net.Listener) {
+			served <- server.Serve(listener)
+		}(server, listeners[i])
+	}
+	a.accepting.Store(true)
+	log.Printf("Listening on %s, metrics on %s", servers[0].Addr, servers[1].Addr)
+
+	var errs []error
+	select {
+	case err := <-served:
+		errs = append(errs, err)
+		a.accepting.Store(false)
+	case <-ctx.Done():
+		log.Println("Shutting down")
+		a.accepting.Store(false)
+		time.Sleep(seconds(a.Config.Server.ShutdownDelaySeconds))
+	}
+
+	// the API drains first so the metrics stay scrapable while it does
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), seconds(a.Config.Server.ShutdownTimeoutSeconds))
+	defer cancel()
+	for _, server := range servers {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("draining requests on %s: %w", server.Addr, err))
+		}
+	}
+	errs = append(errs, a.Close(shutdownCtx))
+	return errors.Join(errs...)
+}
+
+// listen opens the listener of every server, closing those already opened if one fails.
+func listen(servers []*http.Server) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(servers))
+	for _, server := range servers {
+		listener, err := net.Listen("tcp", server.Addr)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+// dependencyChecks lists what /readyz pings before reporting the app ready.
+func (a *App) dependencyChecks() []controller.DependencyCheck {
+	return []controller.DependencyCheck{
+		{Name: "mongodb", Ping: func(ctx context.Context) error {
+			return a.Mongo.Ping(ctx, readpref.Primary())
+		}},
+		{Name: "redis", Ping: func(ctx context.Context) error {
+			return a.Redis.Ping(ctx).Err()
+		}},
+	}
+}
+
+func (a *App) newServer() *http.Server {
+	return &http.Server{
+		Addr:              ":" + a.Config.Server.Port,
+		Handler:           a.Router(),
+		ReadHeaderTimeout: seconds(a.Config.Server.ReadTimeoutSeconds),
+		ReadTimeout:       seconds(a.Config.Server.ReadTimeoutSeconds),
+		WriteTimeout:      seconds(a.Config.Server.WriteTimeoutSeconds),
+		IdleTimeout:       seconds(a.Config.Server.IdleTimeoutSeconds),
+	}
+}
+
+func (a *App) newMetricsServer() *http.Server {
+	return &http.Server{
+		Addr:              ":" + a.Config.Server.MetricsPort,
+		Handler:           a.MetricsRouter(),
+		ReadHeaderTimeout: seconds(a.Config.Server.ReadTimeoutSeconds),
+		ReadTimeout:       seconds(a.Config.Server.ReadTimeoutSeconds),
+		WriteTimeout:      seconds(a.Config.Server.WriteTimeoutSeconds),
+		IdleTimeout:       seconds(a.Config.Server.IdleTimeoutSeconds),
+	}
+}
+
+// MetricsRouter serves /metrics. It has no authentication, so it listens on its own port rather than next to the API.
+func (a *App) MetricsRouter() *gin.Engine {
+	router := gin.New()
+	router.GET("/metrics", metrics.Handler())
+	return router
+}
+
+// Router builds the gin engine with every route group and its middleware.
+func (a *App) Router() *gin.Engine {
 	router := gin.New()
 
-	// apply middleware
-	router.Use(gin.Logger())
+	// probes are registered before the middleware so they stay out of the request log and metrics
+	route.HealthRoutes(router.Group(""), a.accepting.Load, a.dependencyChecks()...)
 
-	//run database
-	database.ConnectDB()
-	redis.InitRedis()
+	// apply middleware; otelgin continues the caller's W3C trace context and starts the request span
+	router.Use(otelgin.Middleware(a.Config.Tracing.ServiceName), gin.Logger(), metrics.Middleware())
 
-	// apply routes
+	// apply routes; the provider endpoints share the limits of the sign-in routes
+	h := controller.NewHandler(a.Store, a.Redis, a.Features)
+	authLimits := rateLimitPolicy("auth", a.Config.RateLimits.Auth)
 	public := router.Group("/api")
 	{
-		route.AuthRoutes(public)
+		public.Use(middleware.RateLimitMiddleware(a.Redis, authLimits))
+		route.AuthRoutes(public, h)
 	}
 	protected := router.Group("/api")
 	{
-		protected.Use(middleware.JwtAuthMiddleware())
-		route.OrganizationRoutes(protected)
-		route.ProtectedUderRoutes(protected)
+		protected.Use(middleware.JwtAuthMiddleware(a.Store, a.Redis, a.Features.Tokens, a.Features.Admins))
+		protected.Use(middleware.RateLimitMiddleware(a.Redis, rateLimitPolicy("organization", a.Config.RateLimits.Organization)))
+		route.OrganizationRoutes(protected, h)
+		route.ProtectedUderRoutes(protected, h)
+		route.APIKeyRoutes(protected, h)
+		route.ServiceAccountRoutes(protected, h)
+	}
+	admin := router.Group("/api/admin")
+	{
+		admin.Use(middleware.JwtAuthMiddleware(a.Store, a.Redis, a.Features.Tokens, a.Features.Admins), middleware.AdminOnlyMiddleware(a.Store, a.Features.Admins))
+		route.AdminRoutes(admin, h)
+		route.OIDCClientAdminRoutes(admin, h)
+	}
+	scim := router.Group("/scim/v2")
+	{
+		scim.Use(middleware.JwtAuthMiddleware(a.Store, a.Redis, a.Features.Tokens, a.Features.Admins),
+			middleware.AdminScopeMiddleware(a.Store, a.Features.Admins, util.ScopeSCIMProvision))
+		route.SCIMRoutes(scim, h)
+	}
+	provider := router.Group("")
+	{
+		provider.Use(middleware.RateLimitMiddleware(a.Redis, authLimits))
+		route.OIDCRoutes(provider, h)
+	}
+
+	return router
+}
+
+// StartApplication connects to the dependencies and serves the API until SIGINT or SIGTERM.
+func StartApplication() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	app, err := OpenApp(ctx)
+	cancel()
+	if err != nil {
+		return err
+	}
+	log.Println("Connected to MongoDB and Redis")
+
+	// run the server until a shutdown signal arrives
+	signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	return app.Serve(signalCtx)
+}
+
+// newFeatures builds each feature from its section of the configuration, so a setting the features
+// refuse stops the server at startup rather than failing requests later.
+func newFeatures(cfg *config.Config) (controller.Features, error) {
+	var features controller.Features
+	var err error
+	if features.Tokens, err = util.NewTokenVerifierFromSettings(tokenSettings(cfg.Token)); err != nil {
+		return features, fmt.Errorf("configuring tokens: %w", err)
+	}
+	if features.PasswordHashing, err = util.NewPasswordHashing(passwordHashingSettings(cfg.PasswordHashing)); err != nil {
+		return features, fmt.Errorf("configuring password hashing: %w", err)
 	}
+	if features.PasswordPolicy, err = util.NewPasswordPolicy(passwordPolicySettings(cfg.PasswordPolicy)); err != nil {
+		return features, fmt.Errorf("configuring the password policy: %w", err)
+	}
+	if features.LoginPolicy, err = util.NewLoginPolicy(util.LoginPolicySettings{
+		MaxAccountAttempts: int64(cfg.Login.MaxAccountAttempts),
+		MaxIPAttempts:      int64(cfg.Login.MaxIPAttempts),
+		Window:             time.Duration(cfg.Login.AttemptWindowMinutes) * time.Minute,
+		Lockout:            time.Duration(cfg.Login.LockoutMinutes) * time.Minute,
+	}); err != nil {
+		return features, fmt.Errorf("configuring sign-in throttling: %w", err)
+	}
+	if features.Admins, err = util.NewAdminSettings(util.AdminSettings{
+		Emails:                cfg.Admin.Emails,
+		ImpersonationLifespan: time.Duration(cfg.Admin.ImpersonationTokenMinutes) * time.Minute,
+	}); err != nil {
+		return features, fmt.Errorf("configuring administrators: %w", err)
+	}
+	if features.OIDC, err = util.LoadOIDCSigner(util.OIDCSettings{Issuer: cfg.OIDC.Issuer, PrivateKeyPath: cfg.OIDC.PrivateKeyPath}); err != nil {
+		return features, fmt.Errorf("configuring OpenID Connect: %w", err)
+	}
+	if features.DomainVerifier, err = util.NewDomainVerifier(cfg.Domains.Verifier); err != nil {
+		return features, fmt.Errorf("configuring domain verification: %w", err)
+	}
+	features.Federation = util.NewFederatedProviders(federatedProviderConfigs(cfg.Federation))
+	return features, nil
+}
+
+// tokenSettings converts the token section of the configuration into verifier settings.
+func tokenSettings(cfg config.TokenConfig) util.TokenSettings {
+	return util.TokenSettings{
+		Secret:   cfg.Secret,
+		Lifespan: time.Duration(cfg.LifespanHours) * time.Hour,
+		Leeway:   seconds(cfg.LeewaySeconds),
+		Issuer:   cfg.Issuer,
+		Audience: cfg.Audience,
+	}
+}
+
+// passwordHashingSettings converts the password_hashing section of the configuration, which Validate
+// has kept within the hashers' ranges, into hasher settings.
+func passwordHashingSettings(cfg config.PasswordHashingConfig) util.PasswordHashingSettings {
+	return util.PasswordHashingSettings{
+		Algorithm:         cfg.Algorithm,
+		Argon2MemoryKiB:   uint32(cfg.Argon2MemoryKiB),
+		Argon2Iterations:  uint32(cfg.Argon2Iterations),
+		Argon2Parallelism: uint8(cfg.Argon2Parallelism),
+		BcryptCost:        cfg.BcryptCost,
+	}
+}
+
+// passwordPolicySettings converts the password_policy section of the configuration into policy settings.
+func passwordPolicySettings(cfg config.PasswordPolicyConfig) util.PasswordPolicySettings {
+	return util.PasswordPolicySettings{
+		MinLength:                cfg.MinLength,
+		MaxLength:                cfg.MaxLength,
+		RequireUppercase:         cfg.RequireUppercase,
+		RequireLowercase:         cfg.RequireLowercase,
+		RequireDigit:             cfg.RequireDigit,
+		RequireSymbol:            cfg.RequireSymbol,
+		DisallowUserInfo:         cfg.DisallowUserInfo,
+		BreachedPasswordsDir:     cfg.BreachedPasswordsDir,
+		BreachedPasswordMinCount: cfg.BreachedPasswordMinCount,
+	}
+}
+
+// rateLimitPolicy converts a route group's rate_limits section into its limits.
+func rateLimitPolicy(name string, cfg config.RateLimitConfig) util.RateLimitPolicy {
+	return util.NewRateLimitPolicy(name, int64(cfg.IPRequests), int64(cfg.UserRequests), seconds(cfg.WindowSeconds))
+}
+
+// federatedProviderConfigs converts the federation section of the configuration into provider configs.
+func federatedProviderConfigs(cfg config.FederationConfig) map[string]util.FederatedProviderConfig {
+	configs := make(map[string]util.FederatedProviderConfig, len(cfg.Providers))
+	for name, provider := range cfg.Providers {
+		configs[name] = util.FederatedProviderConfig{
+			Name:         name,
+			IssuerURL:    provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  provider.RedirectURL,
+			Scopes:       provider.Scopes,
+		}
+	}
+	return configs
+}
 
-	// run the server
-	router.Run(":" + port)
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
 }
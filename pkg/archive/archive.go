@@ -0,0 +1,436 @@
+// Package archive exports an organization with everything that belongs to it into a versioned JSON archive
+// and restores such archives, possibly in another deployment. It is shared by the HTTP API and the CLI.
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+	repository "organization_management/pkg/database/mongodb/repository"
+	util "organization_management/pkg/utils"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrOrganizationNotFound is returned by Export when there is no organization with the id.
+var ErrOrganizationNotFound = errors.New("Organization not found")
+
+// ErrOrganizationExists is returned by Import in fail mode when an organization with the same name exists.
+var ErrOrganizationExists = errors.New("An organization with this name already exists")
+
+// ImportResult describes what an import created. IDMap maps each id from the archive to the id it got in
+// this deployment, for the organization, invite links, join requests, service accounts and audit events.
+type ImportResult struct {
+	OrganizationId       string            `json:"organization_id"`
+	SourceOrganizationId string            `json:"source_organization_id"`
+	Name                 string            `json:"name"`
+	Skipped              bool              `json:"skipped"`
+	IDMap                map[string]string `json:"id_map"`
+	Warnings             []string          `json:"warnings"`
+}
+
+// Export collects the organization and everything that belongs to it.
+func Export(ctx context.Context, store *repository.Store, orgID string) (*util.OrganizationArchive, error) {
+	org, err := store.GetOrganizationByID(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("loading organization: %w", err)
+	}
+	if org == nil {
+		return nil, ErrOrganizationNotFound
+	}
+	domains, err := store.GetOrganizationDomainsByOrganizationID(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("loading domains: %w", err)
+	}
+	links, err := store.GetInviteLinksByOrganizationID(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("loading invite links: %w", err)
+	}
+	requests, err := store.GetJoinRequestsByOrganizationID(ctx, orgID, "")
+	if err != nil {
+		return nil, fmt.Errorf("loading join requests: %w", err)
+	}
+	accounts, err := store.GetServiceAccountsByOrganizationID(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("loading service accounts: %w", err)
+	}
+	events, _, err := store.FindAuditEvents(ctx, bson.M{"target_type": model.AuditTargetOrganization, "target_id": orgID}, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("loading audit events: %w", err)
+	}
+
+	archive := &util.OrganizationArchive{
+		Version:    util.OrganizationArchiveVersion,
+		ExportedAt: time.Now().UTC(),
+		Organization: util.ArchivedOrganization{
+			OrganizationId: org.OrganizationId,
+			Name:           org.Name,
+			Description:    org.Description,
+			Discoverable:   org.Discoverable,
+			Members:        []util.ArchivedMember{},
+		},
+		Domains:         []util.ArchivedDomain{},
+		InviteLinks:     []util.ArchivedInviteLink{},
+		JoinRequests:    []util.ArchivedJoinRequest{},
+		ServiceAccounts: []util.ArchivedServiceAccount{},
+		AuditEvents:     []util.ArchivedAuditEvent{},
+	}
+	for _, member := range org.OrganizationMembers {
+		archive.Organization.Members = append(archive.Organization.Members, util.ArchivedMember{
+			Name:        member.Name,
+			Email:       member.UserEmail,
+			AccessLevel: member.AccessLevel,
+			Type:        member.Type,
+		})
+	}
+	for _, domain := range domains {
+		archive.Domains = append(archive.Domains, util.ArchivedDomain{
+			Domain:             domain.Domain,
+			VerificationToken:  domain.VerificationToken,
+			Verified:           domain.Verified,
+			AutoJoin:           domain.AutoJoin,
+			DefaultAccessLevel: domain.DefaultAccessLevel,
+			CreatedAt:          domain.CreatedAt,
+			VerifiedAt:         domain.VerifiedAt,
+		})
+	}
+	for _, link := range links {
+		archive.InviteLinks = append(archive.InviteLinks, util.ArchivedInviteLink{
+			LinkId:      link.LinkId,
+			HashedToken: link.HashedToken,
+			AccessLevel: link.AccessLevel,
+			MaxUses:     link.MaxUses,
+			Uses:        link.Uses,
+			CreatedBy:   link.CreatedBy,
+			CreatedAt:   link.CreatedAt,
+			ExpiresAt:   link.ExpiresAt,
+			RevokedAt:   link.RevokedAt,
+		})
+	}
+	for _, request := range requests {
+		archive.JoinRequests = append(archive.JoinRequests, util.ArchivedJoinRequest{
+			RequestId: request.RequestId,
+			UserEmail: request.UserEmail,
+			UserName:  request.UserName,
+			Message:   request.Message,
+			Status:    request.Status,
+			CreatedAt: request.CreatedAt,
+			DecidedBy: request.DecidedBy,
+			DecidedAt: request.DecidedAt,
+		})
+	}
+	for _, account := range accounts {
+		archive.ServiceAccounts = append(archive.ServiceAccounts, util.ArchivedServiceAccount{
+			ClientId:     account.ClientId,
+			Name:         account.Name,
+			AccessLevel:  account.AccessLevel,
+			HashedSecret: account.HashedSecret,
+			Disabled:     account.Disabled,
+			CreatedAt:    account.CreatedAt,
+			DisabledAt:   account.DisabledAt,
+		})
+	}
+	for _, event := range events {
+		archive.AuditEvents = append(archive.AuditEvents, util.ArchivedAuditEvent{
+			EventId:    event.EventId,
+			ActorId:    event.ActorId,
+			ActorEmail: event.ActorEmail,
+			Action:     event.Action,
+			Details:    event.Details,
+			ClientIP:   event.ClientIP,
+			CreatedAt:  event.CreatedAt,
+		})
+	}
+	return archive, nil
+}
+
+// Import recreates an archived organization under new ids. onConflict decides what happens when an
+// organization with the same name exists: fail returns ErrOrganizationExists, skip leaves everything as
+// is, and rename imports it under a new name. Records that clash with this deployment are adjusted and
+// reported as warnings: service accounts get a new client id, domains verified by another organization
+// are imported unverified, and join requests of unknown users are dropped. If a step fails, everything
+// created so far is removed again.
+func Import(ctx context.Context, store *repository.Store, archive *util.OrganizationArchive, onConflict string) (*ImportResult, error) {
+	if !util.ValidArchiveConflict(onConflict) {
+		return nil, util.ErrInvalidArchiveConflict
+	}
+
+	result := &ImportResult{
+		SourceOrganizationId: archive.Organization.OrganizationId,
+		Name:                 archive.Organization.Name,
+		IDMap:                map[string]string{},
+		Warnings:             []string{},
+	}
+
+	existing, err := findOrganizationByName(ctx, store, result.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		switch onConflict {
+		case util.ArchiveConflictFail:
+			return nil, ErrOrganizationExists
+		case util.ArchiveConflictSkip:
+			result.OrganizationId = existing.OrganizationId
+			result.Skipped = true
+			return result, nil
+		}
+		if result.Name, err = availableName(ctx, store, result.Name); err != nil {
+			return nil, err
+		}
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Organization renamed to %q", result.Name))
+	}
+
+	accounts, memberEmails, err := remapServiceAccounts(ctx, store, archive.ServiceAccounts, result)
+	if err != nil {
+		return nil, err
+	}
+
+	org := model.Organization{
+		Name:         result.Name,
+		Description:  archive.Organization.Description,
+		Discoverable: archive.Organization.Discoverable,
+	}
+	for _, member := range archive.Organization.Members {
+		email := member.Email
+		if renamed, ok := memberEmails[email]; ok {
+			email = renamed
+		}
+		org.OrganizationMembers = append(org.OrganizationMembers, model.OrganizationMember{
+			Name:        member.Name,
+			UserEmail:   email,
+			AccessLevel: member.AccessLevel,
+			Type:        member.Type,
+		})
+	}
+	if err := warnAboutMissingUsers(ctx, store, org.OrganizationMembers, result); err != nil {
+		return nil, err
+	}
+
+	_, orgID, err := store.InsertOrganization(ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("creating organization: %w", err)
+	}
+	result.OrganizationId = orgID
+	result.IDMap[archive.Organization.OrganizationId] = orgID
+
+	if err := importRecords(ctx, store, archive, orgID, accounts, result); err != nil {
+		Rollback(ctx, store, orgID)
+		return nil, err
+	}
+	return result, nil
+}
+
+// remapServiceAccounts prepares the archived service accounts for insertion, giving a new client id to those
+// whose id is taken in this deployment. It returns the old to new member email of every renamed account.
+func remapServiceAccounts(ctx context.Context, store *repository.Store, archived []util.ArchivedServiceAccount, result *ImportResult) ([]model.ServiceAccount, map[string]string, error) {
+	accounts := []model.ServiceAccount{}
+	memberEmails := map[string]string{}
+	for _, account := range archived {
+		imported := model.ServiceAccount{
+			ClientId:     account.ClientId,
+			Name:         account.Name,
+			AccessLevel:  account.AccessLevel,
+			HashedSecret: account.HashedSecret,
+			Disabled:     account.Disabled,
+			CreatedAt:    account.CreatedAt,
+			DisabledAt:   account.DisabledAt,
+		}
+
+		taken, err := store.GetServiceAccountByClientID(ctx, account.ClientId)
+		if err != nil {
+			return nil, nil, fmt.Errorf("checking service account %s: %w", account.ClientId, err)
+		}
+		if taken != nil {
+			clientID, _, _, err := util.GenerateClientCredentials("sa_")
+			if err != nil {
+				return nil, nil, err
+			}
+			previousEmail := imported.Email()
+			imported.ClientId = clientID
+			memberEmails[previousEmail] = imported.Email()
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Service account %s was given the client id %s", account.ClientId, clientID))
+		}
+		result.IDMap[account.ClientId] = imported.ClientId
+		accounts = append(accounts, imported)
+	}
+	return accounts, memberEmails, nil
+}
+
+func importRecords(ctx context.Context, store *repository.Store, archive *util.OrganizationArchive, orgID string, accounts []model.ServiceAccount, result *ImportResult) error {
+	for _, account := range accounts {
+		account.OrganizationId = orgID
+		if err := store.InsertServiceAccount(ctx, account); err != nil {
+			return fmt.Errorf("creating service account %s: %w", account.ClientId, err)
+		}
+	}
+
+	for _, domain := range archive.Domains {
+		imported := model.OrganizationDomain{
+			OrganizationId:     orgID,
+			Domain:             domain.Domain,
+			VerificationToken:  domain.VerificationToken,
+			Verified:           domain.Verified,
+			AutoJoin:           domain.AutoJoin,
+			DefaultAccessLevel: domain.DefaultAccessLevel,
+			CreatedAt:          domain.CreatedAt,
+			VerifiedAt:         domain.VerifiedAt,
+		}
+		err := store.InsertOrganizationDomain(ctx, imported)
+		if errors.Is(err, repository.ErrDomainAlreadyVerified) {
+			imported.Verified = false
+			imported.VerifiedAt = nil
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Domain %s is verified by another organization and was imported unverified", domain.Domain))
+			err = store.InsertOrganizationDomain(ctx, imported)
+		}
+		if err != nil {
+			return fmt.Errorf("creating domain %s: %w", domain.Domain, err)
+		}
+	}
+
+	for _, link := range archive.InviteLinks {
+		taken, err := store.GetInviteLinkByHash(ctx, link.HashedToken)
+		if err != nil {
+			return fmt.Errorf("checking invite link %s: %w", link.LinkId, err)
+		}
+		if taken != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Invite link %s already exists in this deployment and was skipped", link.LinkId))
+			continue
+		}
+		imported := model.InviteLink{
+			LinkId:         uuid.New().String(),
+			OrganizationId: orgID,
+			HashedToken:    link.HashedToken,
+			AccessLevel:    link.AccessLevel,
+			MaxUses:        link.MaxUses,
+			Uses:           link.Uses,
+			CreatedBy:      link.CreatedBy,
+			CreatedAt:      link.CreatedAt,
+			ExpiresAt:      link.ExpiresAt,
+			RevokedAt:      link.RevokedAt,
+		}
+		if err := store.InsertInviteLink(ctx, imported); err != nil {
+			return fmt.Errorf("creating invite link %s: %w", link.LinkId, err)
+		}
+		result.IDMap[link.LinkId] = imported.LinkId
+	}
+
+	for _, request := range archive.JoinRequests {
+		user, err := store.GetUserByEmail(ctx, request.UserEmail)
+		if err != nil {
+			return fmt.Errorf("looking up %s: %w", request.UserEmail, err)
+		}
+		if user == nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Join request %s was dropped because %s has no account", request.RequestId, request.UserEmail))
+			continue
+		}
+		imported := model.JoinRequest{
+			RequestId:      uuid.New().String(),
+			OrganizationId: orgID,
+			UserId:         user.Id.Hex(),
+			UserEmail:      user.Email,
+			UserName:       request.UserName,
+			Message:        request.Message,
+			Status:         request.Status,
+			CreatedAt:      request.CreatedAt,
+			DecidedBy:      request.DecidedBy,
+			DecidedAt:      request.DecidedAt,
+		}
+		if err := store.InsertJoinRequest(ctx, imported); err != nil {
+			return fmt.Errorf("creating join request %s: %w", request.RequestId, err)
+		}
+		result.IDMap[request.RequestId] = imported.RequestId
+	}
+
+	for _, event := range archive.AuditEvents {
+		imported := model.AuditEvent{
+			EventId:    uuid.New().String(),
+			ActorId:    event.ActorId,
+			ActorEmail: event.ActorEmail,
+			Action:     event.Action,
+			TargetType: model.AuditTargetOrganization,
+			TargetId:   orgID,
+			Details:    event.Details,
+			ClientIP:   event.ClientIP,
+			CreatedAt:  event.CreatedAt,
+			Imported:   true,
+		}
+		if err := store.InsertAuditEvent(ctx, imported); err != nil {
+			return fmt.Errorf("creating audit event %s: %w", event.EventId, err)
+		}
+		result.IDMap[event.EventId] = imported.EventId
+	}
+	return nil
+}
+
+// warnAboutMissingUsers reports members who don't have an account in this deployment yet. They keep their
+// membership and get access once they sign up with that email.
+func warnAboutMissingUsers(ctx context.Context, store *repository.Store, members []model.OrganizationMember, result *ImportResult) error {
+	var emails []string
+	for _, member := range members {
+		if member.Type != model.MemberTypeServiceAccount {
+			emails = append(emails, member.UserEmail)
+		}
+	}
+	if len(emails) == 0 {
+		return nil
+	}
+
+	users, _, err := store.FindUsers(ctx, bson.M{"email": bson.M{"$in": emails}}, 0, 0)
+	if err != nil {
+		return fmt.Errorf("looking up members: %w", err)
+	}
+	known := map[string]bool{}
+	for _, user := range users {
+		known[user.Email] = true
+	}
+	for _, email := range emails {
+		if !known[email] {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Member %s has no account in this deployment yet", email))
+		}
+	}
+	return nil
+}
+
+func findOrganizationByName(ctx context.Context, store *repository.Store, name string) (*model.Organization, error) {
+	orgs, _, err := store.FindOrganizations(ctx, bson.M{"name": name}, 0, 1)
+	if err != nil {
+		return nil, fmt.Errorf("checking for existing organization: %w", err)
+	}
+	if len(orgs) == 0 {
+		return nil, nil
+	}
+	return &orgs[0], nil
+}
+
+// availableName returns the first of "<name> (imported)", "<name> (imported 2)", ... that is not taken.
+func availableName(ctx context.Context, store *repository.Store, name string) (string, error) {
+	for i := 1; ; i++ {
+		candidate := name + " (imported)"
+		if i > 1 {
+			candidate = fmt.Sprintf("%s (imported %d)", name, i)
+		}
+		existing, err := findOrganizationByName(ctx, store, candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+	}
+}
+
+// Rollback removes an imported organization and everything imported with it. Errors are ignored since it
+// only runs once the import has failed.
+func Rollback(ctx context.Context, store *repository.Store, orgID string) {
+	_ = store.DeleteServiceAccountsByOrganizationID(ctx, orgID)
+	_ = store.DeleteOrganizationDomainsByOrganizationID(ctx, orgID)
+	_ = store.DeleteInviteLinksByOrganizationID(ctx, orgID)
+	_ = store.DeleteJoinRequestsByOrganizationID(ctx, orgID)
+	_ = store.DeleteImportedAuditEvents(ctx, orgID)
+	_ = store.DeleteOrganization(ctx, orgID)
+}
@@ -0,0 +1,616 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFiles are read from the config directory, in order; later files override earlier ones.
+var DefaultFiles = []string{"app-config.yaml", "database-config.yaml"}
+
+// Config holds the settings the server needs to start.
+type Config struct {
+	Server  ServerConfig  `yaml:"server"`
+	Mongo   MongoConfig   `yaml:"mongodb"`
+	Redis   RedisConfig   `yaml:"redis"`
+	Token   TokenConfig   `yaml:"token"`
+	Tracing TracingConfig `yaml:"tracing"`
+
+	PasswordHashing PasswordHashingConfig `yaml:"password_hashing"`
+	PasswordPolicy  PasswordPolicyConfig  `yaml:"password_policy"`
+	Login           LoginConfig           `yaml:"login"`
+	RateLimits      RateLimitsConfig      `yaml:"rate_limits"`
+	Admin           AdminConfig           `yaml:"admin"`
+	OIDC            OIDCConfig            `yaml:"oidc"`
+	Federation      FederationConfig      `yaml:"federation"`
+	Domains         DomainsConfig         `yaml:"domains"`
+}
+
+// ServerConfig configures the HTTP listeners and how long they wait for requests to finish on shutdown.
+// Metrics are served on MetricsPort, apart from the API, so they can be kept off the public network.
+type ServerConfig struct {
+	Port                   string `yaml:"port"`
+	MetricsPort            string `yaml:"metrics_port"`
+	ReadTimeoutSeconds     int    `yaml:"read_timeout_seconds"`
+	WriteTimeoutSeconds    int    `yaml:"write_timeout_seconds"`
+	IdleTimeoutSeconds     int    `yaml:"idle_timeout_seconds"`
+	ShutdownTimeoutSeconds int    `yaml:"shutdown_timeout_seconds"`
+	ShutdownDelaySeconds   int    `yaml:"shutdown_delay_seconds"`
+}
+
+// MongoConfig configures the MongoDB connection.
+type MongoConfig struct {
+	URI      string `yaml:"uri"`
+	Database string `yaml:"database"`
+}
+
+// RedisConfig configures the Redis connection.
+type RedisConfig struct {
+	Addr     string `yaml:"addr"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+}
+
+// TokenConfig configures how access and refresh tokens are signed and checked.
+type TokenConfig struct {
+	Secret        string `yaml:"secret"`
+	LifespanHours int    `yaml:"lifespan_hours"`
+	LeewaySeconds int    `yaml:"leeway_seconds"`
+	Issuer        string `yaml:"issuer"`
+	Audience      string `yaml:"audience"`
+}
+
+// Span exporters the tracing section can select.
+const (
+	TracingExporterNone   = "none"
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+// TracingConfig configures OpenTelemetry tracing. OTLPEndpoint is the collector's OTLP/HTTP base URL;
+// when empty the exporter's default, http://localhost:4318, is used.
+type TracingConfig struct {
+	Exporter     string  `yaml:"exporter"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint"`
+	ServiceName  string  `yaml:"service_name"`
+	SampleRatio  float64 `yaml:"sample_ratio"`
+}
+
+// Password hashing algorithms the password_hashing section can select.
+const (
+	PasswordHashArgon2id = "argon2id"
+	PasswordHashBcrypt   = "bcrypt"
+)
+
+// PasswordHashingConfig selects the algorithm new password hashes use and the parameters of each algorithm.
+type PasswordHashingConfig struct {
+	Algorithm         string `yaml:"algorithm"`
+	Argon2MemoryKiB   int    `yaml:"argon2_memory_kib"`
+	Argon2Iterations  int    `yaml:"argon2_iterations"`
+	Argon2Parallelism int    `yaml:"argon2_parallelism"`
+	BcryptCost        int    `yaml:"bcrypt_cost"`
+}
+
+// bcryptMaxPasswordBytes is the longest password bcrypt can hash, and so the longest a password may be.
+const bcryptMaxPasswordBytes = 72
+
+// PasswordPolicyConfig describes what a new password must look like. The breached password check is
+// enabled by pointing BreachedPasswordsDir at a range directory.
+type PasswordPolicyConfig struct {
+	MinLength                int    `yaml:"min_length"`
+	MaxLength                int    `yaml:"max_length"`
+	RequireUppercase         bool   `yaml:"require_uppercase"`
+	RequireLowercase         bool   `yaml:"require_lowercase"`
+	RequireDigit             bool   `yaml:"require_digit"`
+	RequireSymbol            bool   `yaml:"require_symbol"`
+	DisallowUserInfo         bool   `yaml:"disallow_user_info"`
+	BreachedPasswordsDir     string `yaml:"breached_passwords_dir"`
+	BreachedPasswordMinCount int    `yaml:"breached_password_min_count"`
+}
+
+// LoginConfig controls how failed sign-in attempts are throttled.
+type LoginConfig struct {
+	MaxAccountAttempts   int `yaml:"max_account_attempts"`
+	MaxIPAttempts        int `yaml:"max_ip_attempts"`
+	AttemptWindowMinutes int `yaml:"attempt_window_minutes"`
+	LockoutMinutes       int `yaml:"lockout_minutes"`
+}
+
+// RateLimitsConfig holds the limits of each rate limited route group.
+type RateLimitsConfig struct {
+	Auth         RateLimitConfig `yaml:"auth"`
+	Organization RateLimitConfig `yaml:"organization"`
+}
+
+// RateLimitConfig allows a number of requests per client address and per user in each window.
+// A number of requests of 0 turns that limit off.
+type RateLimitConfig struct {
+	IPRequests    int `yaml:"ip_requests"`
+	UserRequests  int `yaml:"user_requests"`
+	WindowSeconds int `yaml:"window_seconds"`
+}
+
+// AdminConfig names the platform administrators and how long their impersonation tokens last.
+type AdminConfig struct {
+	Emails                    []string `yaml:"emails"`
+	ImpersonationTokenMinutes int      `yaml:"impersonation_token_minutes"`
+}
+
+// OIDCConfig configures the OpenID Connect provider. Without a PrivateKeyPath an ephemeral signing key is
+// generated, which invalidates issued ID tokens on every restart and is only meant for local use.
+type OIDCConfig struct {
+	Issuer         string `yaml:"issuer"`
+	PrivateKeyPath string `yaml:"private_key_path"`
+}
+
+// FederationConfig lists the upstream identity providers users can sign in with, by name.
+type FederationConfig struct {
+	Providers map[string]FederatedProviderConfig `yaml:"providers"`
+}
+
+// FederatedProviderConfig describes an upstream OpenID Connect identity provider.
+type FederatedProviderConfig struct {
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes"`
+}
+
+// DefaultFederatedScopes are requested from providers that don't list their own scopes.
+var DefaultFederatedScopes = []string{"openid", "email", "profile"}
+
+// Domain verifiers the domains section can select.
+const (
+	DomainVerifierDNS  = "dns"
+	DomainVerifierStub = "stub"
+)
+
+// DomainsConfig selects how domain claims are checked. The stub verifier accepts every domain and must
+// never be used in production.
+type DomainsConfig struct {
+	Verifier string `yaml:"verifier"`
+}
+
+// Default returns the settings used when neither a file nor the environment sets a value.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:                   "8080",
+			MetricsPort:            "9090",
+			ReadTimeoutSeconds:     30,
+			WriteTimeoutSeconds:    75,
+			IdleTimeoutSeconds:     120,
+			ShutdownTimeoutSeconds: 30,
+			ShutdownDelaySeconds:   5,
+		},
+		Redis: RedisConfig{Addr: "localhost:6379"},
+		Token: TokenConfig{
+			LifespanHours: 1,
+			LeewaySeconds: 30,
+			Issuer:        "organization_management",
+			Audience:      "organization_management_api",
+		},
+		Tracing: TracingConfig{
+			Exporter:    TracingExporterNone,
+			ServiceName: "organization_management",
+			SampleRatio: 1,
+		},
+		// Argon2id with the parameters recommended by OWASP
+		PasswordHashing: PasswordHashingConfig{
+			Algorithm:         PasswordHashArgon2id,
+			Argon2MemoryKiB:   19456,
+			Argon2Iterations:  2,
+			Argon2Parallelism: 1,
+			BcryptCost:        12,
+		},
+		PasswordPolicy: PasswordPolicyConfig{
+			MinLength:                8,
+			MaxLength:                bcryptMaxPasswordBytes,
+			DisallowUserInfo:         true,
+			BreachedPasswordMinCount: 1,
+		},
+		Login: LoginConfig{
+			MaxAccountAttempts:   5,
+			MaxIPAttempts:        20,
+			AttemptWindowMinutes: 15,
+			LockoutMinutes:       15,
+		},
+		RateLimits: RateLimitsConfig{
+			Auth:         RateLimitConfig{IPRequests: 30, WindowSeconds: 60},
+			Organization: RateLimitConfig{IPRequests: 300, UserRequests: 120, WindowSeconds: 60},
+		},
+		Admin:   AdminConfig{ImpersonationTokenMinutes: 15},
+		OIDC:    OIDCConfig{Issuer: "http://localhost:8080"},
+		Domains: DomainsConfig{Verifier: DomainVerifierDNS},
+	}
+}
+
+// LoadDefault loads the configuration from the DefaultFiles in CONFIG_DIR (default "config") and the environment.
+func LoadDefault() (*Config, error) {
+	dir := os.Getenv("CONFIG_DIR")
+	if dir == "" {
+		dir = "config"
+	}
+	paths := make([]string, 0, len(DefaultFiles))
+	for _, name := range DefaultFiles {
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	return Load(paths...)
+}
+
+// Load starts from the defaults, applies each YAML file that exists, then the environment overrides,
+// and validates the result. Every problem found is reported in the returned error.
+func Load(paths ...string) (*Config, error) {
+	cfg := Default()
+	var errs []error
+	for _, path := range paths {
+		if err := cfg.applyFile(path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, cfg.applyEnv()...)
+	cfg.normalize()
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) applyFile(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overrides file settings with the environment variables the server has always read.
+func (c *Config) applyEnv() []error {
+	var errs []error
+	setString := func(key string, target *string) {
+		if value := os.Getenv(key); value != "" {
+			*target = value
+		}
+	}
+	setInt := func(key string, target *int) {
+		value := os.Getenv(key)
+		if value == "" {
+			return
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be an integer, got %q", key, value))
+			return
+		}
+		*target = parsed
+	}
+	setFloat := func(key string, target *float64) {
+		value := os.Getenv(key)
+		if value == "" {
+			return
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be a number, got %q", key, value))
+			return
+		}
+		*target = parsed
+	}
+	setBool := func(key string, target *bool) {
+		value := os.Getenv(key)
+		if value == "" {
+			return
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s must be true or false, got %q", key, value))
+			return
+		}
+		*target = parsed
+	}
+	// setList reads a comma separated list
+	setList := func(key string, target *[]string) {
+		if value := os.Getenv(key); value != "" {
+			*target = splitList(value)
+		}
+	}
+
+	setString("PORT", &c.Server.Port)
+	setString("METRICS_PORT", &c.Server.MetricsPort)
+	setInt("SERVER_READ_TIMEOUT_SECONDS", &c.Server.ReadTimeoutSeconds)
+	setInt("SERVER_WRITE_TIMEOUT_SECONDS", &c.Server.WriteTimeoutSeconds)
+	setInt("SERVER_IDLE_TIMEOUT_SECONDS", &c.Server.IdleTimeoutSeconds)
+	setInt("SERVER_SHUTDOWN_TIMEOUT_SECONDS", &c.Server.ShutdownTimeoutSeconds)
+	setInt("SERVER_SHUTDOWN_DELAY_SECONDS", &c.Server.ShutdownDelaySeconds)
+	setString("MONGOURI", &c.Mongo.URI)
+	setString("MONGODB_DATABASE_NAME", &c.Mongo.Database)
+	setString("REDIS_ADDR", &c.Redis.Addr)
+	setString("REDIS_PASSWORD", &c.Redis.Password)
+	setInt("REDIS_DB", &c.Redis.DB)
+	setString("API_SECRET", &c.Token.Secret)
+	setInt("TOKEN_HOUR_LIFESPAN", &c.Token.LifespanHours)
+	setInt("TOKEN_LEEWAY_SECONDS", &c.Token.LeewaySeconds)
+	setString("TOKEN_ISSUER", &c.Token.Issuer)
+	setString("TOKEN_AUDIENCE", &c.Token.Audience)
+	setString("TRACING_EXPORTER", &c.Tracing.Exporter)
+	setString("OTEL_EXPORTER_OTLP_ENDPOINT", &c.Tracing.OTLPEndpoint)
+	setString("OTEL_SERVICE_NAME", &c.Tracing.ServiceName)
+	setFloat("TRACING_SAMPLE_RATIO", &c.Tracing.SampleRatio)
+	setString("PASSWORD_HASH_ALGORITHM", &c.PasswordHashing.Algorithm)
+	setInt("PASSWORD_ARGON2_MEMORY_KIB", &c.PasswordHashing.Argon2MemoryKiB)
+	setInt("PASSWORD_ARGON2_ITERATIONS", &c.PasswordHashing.Argon2Iterations)
+	setInt("PASSWORD_ARGON2_PARALLELISM", &c.PasswordHashing.Argon2Parallelism)
+	setInt("PASSWORD_BCRYPT_COST", &c.PasswordHashing.BcryptCost)
+	setInt("PASSWORD_MIN_LENGTH", &c.PasswordPolicy.MinLength)
+	setInt("PASSWORD_MAX_LENGTH", &c.PasswordPolicy.MaxLength)
+	setBool("PASSWORD_REQUIRE_UPPERCASE", &c.PasswordPolicy.RequireUppercase)
+	setBool("PASSWORD_REQUIRE_LOWERCASE", &c.PasswordPolicy.RequireLowercase)
+	setBool("PASSWORD_REQUIRE_DIGIT", &c.PasswordPolicy.RequireDigit)
+	setBool("PASSWORD_REQUIRE_SYMBOL", &c.PasswordPolicy.RequireSymbol)
+	setBool("PASSWORD_DISALLOW_USER_INFO", &c.PasswordPolicy.DisallowUserInfo)
+	setString("BREACHED_PASSWORDS_DIR", &c.PasswordPolicy.BreachedPasswordsDir)
+	setInt("BREACHED_PASSWORD_MIN_COUNT", &c.PasswordPolicy.BreachedPasswordMinCount)
+	setInt("LOGIN_MAX_ACCOUNT_ATTEMPTS", &c.Login.MaxAccountAttempts)
+	setInt("LOGIN_MAX_IP_ATTEMPTS", &c.Login.MaxIPAttempts)
+	setInt("LOGIN_ATTEMPT_WINDOW_MINUTES", &c.Login.AttemptWindowMinutes)
+	setInt("LOGIN_LOCKOUT_MINUTES", &c.Login.LockoutMinutes)
+	for prefix, limit := range map[string]*RateLimitConfig{
+		"RATE_LIMIT_AUTH_":         &c.RateLimits.Auth,
+		"RATE_LIMIT_ORGANIZATION_": &c.RateLimits.Organization,
+	} {
+		setInt(prefix+"IP_REQUESTS", &limit.IPRequests)
+		setInt(prefix+"USER_REQUESTS", &limit.UserRequests)
+		setInt(prefix+"WINDOW_SECONDS", &limit.WindowSeconds)
+	}
+	setList("ADMIN_EMAILS", &c.Admin.Emails)
+	setInt("IMPERSONATION_TOKEN_MINUTES", &c.Admin.ImpersonationTokenMinutes)
+	setString("OIDC_ISSUER", &c.OIDC.Issuer)
+	setString("OIDC_PRIVATE_KEY_PATH", &c.OIDC.PrivateKeyPath)
+	// Each provider <NAME> listed in FEDERATED_PROVIDERS is configured with FEDERATED_<NAME>_ISSUER,
+	// _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL and _SCOPES, on top of what the files say about it
+	// Providers are looked up by the lowercased name in the sign-in URL
+	providers := make(map[string]FederatedProviderConfig, len(c.Federation.Providers))
+	for name, provider := range c.Federation.Providers {
+		providers[strings.ToLower(name)] = provider
+	}
+	c.Federation.Providers = providers
+	var names []string
+	setList("FEDERATED_PROVIDERS", &names)
+	for _, name := range names {
+		name = strings.ToLower(name)
+		provider := c.Federation.Providers[name]
+		prefix := "FEDERATED_" + strings.ToUpper(name) + "_"
+		setString(prefix+"ISSUER", &provider.Issuer)
+		setString(prefix+"CLIENT_ID", &provider.ClientID)
+		setString(prefix+"CLIENT_SECRET", &provider.ClientSecret)
+		setString(prefix+"REDIRECT_URL", &provider.RedirectURL)
+		if scopes := os.Getenv(prefix + "SCOPES"); scopes != "" {
+			provider.Scopes = strings.Fields(scopes)
+		}
+		c.Federation.Providers[name] = provider
+	}
+	setString("DOMAIN_VERIFIER", &c.Domains.Verifier)
+	return errs
+}
+
+// normalize puts values that may be written several ways into the form the server compares against.
+func (c *Config) normalize() {
+	emails := make([]string, 0, len(c.Admin.Emails))
+	for _, email := range c.Admin.Emails {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	c.Admin.Emails = emails
+	c.OIDC.Issuer = strings.TrimSuffix(c.OIDC.Issuer, "/")
+	for name, provider := range c.Federation.Providers {
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = DefaultFederatedScopes
+			c.Federation.Providers[name] = provider
+		}
+	}
+	c.Domains.Verifier = strings.ToLower(c.Domains.Verifier)
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isHTTPURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// Validate reports every setting that is missing or out of range.
+func (c *Config) Validate() error {
+	var errs []error
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("server.port (PORT) must be between 1 and 65535, got %q", c.Server.Port))
+	}
+	if port, err := strconv.Atoi(c.Server.MetricsPort); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("server.metrics_port (METRICS_PORT) must be between 1 and 65535, got %q", c.Server.MetricsPort))
+	} else if c.Server.MetricsPort == c.Server.Port {
+		errs = append(errs, fmt.Errorf("server.metrics_port (METRICS_PORT) must differ from server.port (PORT), got %q for both", c.Server.Port))
+	}
+	for _, timeout := range []struct {
+		name  string
+		value int
+	}{
+		{"server.read_timeout_seconds (SERVER_READ_TIMEOUT_SECONDS)", c.Server.ReadTimeoutSeconds},
+		{"server.write_timeout_seconds (SERVER_WRITE_TIMEOUT_SECONDS)", c.Server.WriteTimeoutSeconds},
+		{"server.idle_timeout_seconds (SERVER_IDLE_TIMEOUT_SECONDS)", c.Server.IdleTimeoutSeconds},
+		{"server.shutdown_timeout_seconds (SERVER_SHUTDOWN_TIMEOUT_SECONDS)", c.Server.ShutdownTimeoutSeconds},
+	} {
+		if timeout.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %d", timeout.name, timeout.value))
+		}
+	}
+	if c.Server.ShutdownDelaySeconds < 0 {
+		errs = append(errs, fmt.Errorf("server.shutdown_delay_seconds (SERVER_SHUTDOWN_DELAY_SECONDS) must not be negative, got %d", c.Server.ShutdownDelaySeconds))
+	}
+	if c.Mongo.URI == "" {
+		errs = append(errs, errors.New("mongodb.uri (MONGOURI) is required"))
+	}
+	if c.Mongo.Database == "" {
+		errs = append(errs, errors.New("mongodb.database (MONGODB_DATABASE_NAME) is required"))
+	}
+	if c.Redis.Addr == "" {
+		errs = append(errs, errors.New("redis.addr (REDIS_ADDR) is required"))
+	}
+	if c.Redis.DB < 0 {
+		errs = append(errs, fmt.Errorf("redis.db (REDIS_DB) must not be negative, got %d", c.Redis.DB))
+	}
+	if c.Token.Secret == "" {
+		errs = append(errs, errors.New("token.secret (API_SECRET) is required"))
+	}
+	if c.Token.LifespanHours <= 0 {
+		errs = append(errs, fmt.Errorf("token.lifespan_hours (TOKEN_HOUR_LIFESPAN) must be positive, got %d", c.Token.LifespanHours))
+	}
+	if c.Token.LeewaySeconds < 0 {
+		errs = append(errs, fmt.Errorf("token.leeway_seconds (TOKEN_LEEWAY_SECONDS) must not be negative, got %d", c.Token.LeewaySeconds))
+	}
+	if c.Token.Issuer == "" {
+		errs = append(errs, errors.New("token.issuer (TOKEN_ISSUER) is required"))
+	}
+	if c.Token.Audience == "" {
+		errs = append(errs, errors.New("token.audience (TOKEN_AUDIENCE) is required"))
+	}
+	switch c.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterOTLP, TracingExporterStdout:
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter (TRACING_EXPORTER) must be none, otlp or stdout, got %q", c.Tracing.Exporter))
+	}
+	if c.Tracing.OTLPEndpoint != "" {
+		if !isHTTPURL(c.Tracing.OTLPEndpoint) {
+			errs = append(errs, fmt.Errorf("tracing.otlp_endpoint (OTEL_EXPORTER_OTLP_ENDPOINT) must be an http or https URL, got %q", c.Tracing.OTLPEndpoint))
+		}
+	}
+	if c.Tracing.ServiceName == "" {
+		errs = append(errs, errors.New("tracing.service_name (OTEL_SERVICE_NAME) is required"))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sample_ratio (TRACING_SAMPLE_RATIO) must be between 0 and 1, got %g", c.Tracing.SampleRatio))
+	}
+	errs = append(errs, c.PasswordHashing.validate()...)
+	errs = append(errs, c.PasswordPolicy.validate()...)
+	for _, limit := range []struct {
+		name  string
+		value int
+	}{
+		{"login.max_account_attempts (LOGIN_MAX_ACCOUNT_ATTEMPTS)", c.Login.MaxAccountAttempts},
+		{"login.max_ip_attempts (LOGIN_MAX_IP_ATTEMPTS)", c.Login.MaxIPAttempts},
+		{"login.attempt_window_minutes (LOGIN_ATTEMPT_WINDOW_MINUTES)", c.Login.AttemptWindowMinutes},
+		{"login.lockout_minutes (LOGIN_LOCKOUT_MINUTES)", c.Login.LockoutMinutes},
+		{"rate_limits.auth.window_seconds (RATE_LIMIT_AUTH_WINDOW_SECONDS)", c.RateLimits.Auth.WindowSeconds},
+		{"rate_limits.organization.window_seconds (RATE_LIMIT_ORGANIZATION_WINDOW_SECONDS)", c.RateLimits.Organization.WindowSeconds},
+		{"admin.impersonation_token_minutes (IMPERSONATION_TOKEN_MINUTES)", c.Admin.ImpersonationTokenMinutes},
+	} {
+		if limit.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %d", limit.name, limit.value))
+		}
+	}
+	for _, requests := range []struct {
+		name  string
+		value int
+	}{
+		{"rate_limits.auth.ip_requests (RATE_LIMIT_AUTH_IP_REQUESTS)", c.RateLimits.Auth.IPRequests},
+		{"rate_limits.auth.user_requests (RATE_LIMIT_AUTH_USER_REQUESTS)", c.RateLimits.Auth.UserRequests},
+		{"rate_limits.organization.ip_requests (RATE_LIMIT_ORGANIZATION_IP_REQUESTS)", c.RateLimits.Organization.IPRequests},
+		{"rate_limits.organization.user_requests (RATE_LIMIT_ORGANIZATION_USER_REQUESTS)", c.RateLimits.Organization.UserRequests},
+	} {
+		if requests.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %d", requests.name, requests.value))
+		}
+	}
+	if !isHTTPURL(c.OIDC.Issuer) {
+		errs = append(errs, fmt.Errorf("oidc.issuer (OIDC_ISSUER) must be an http or https URL, got %q", c.OIDC.Issuer))
+	}
+	names := make([]string, 0, len(c.Federation.Providers))
+	for name := range c.Federation.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		provider := c.Federation.Providers[name]
+		prefix := "FEDERATED_" + strings.ToUpper(name) + "_"
+		if !isHTTPURL(provider.Issuer) {
+			errs = append(errs, fmt.Errorf("federation.providers.%s.issuer (%sISSUER) must be an http or https URL, got %q", name, prefix, provider.Issuer))
+		}
+		if provider.ClientID == "" {
+			errs = append(errs, fmt.Errorf("federation.providers.%s.client_id (%sCLIENT_ID) is required", name, prefix))
+		}
+		if !isHTTPURL(provider.RedirectURL) {
+			errs = append(errs, fmt.Errorf("federation.providers.%s.redirect_url (%sREDIRECT_URL) must be an http or https URL, got %q", name, prefix, provider.RedirectURL))
+		}
+	}
+	switch c.Domains.Verifier {
+	case DomainVerifierDNS, DomainVerifierStub:
+	default:
+		errs = append(errs, fmt.Errorf("domains.verifier (DOMAIN_VERIFIER) must be dns or stub, got %q", c.Domains.Verifier))
+	}
+	return errors.Join(errs...)
+}
+
+func (c PasswordPolicyConfig) validate() []error {
+	var errs []error
+	if c.MinLength < 1 {
+		errs = append(errs, fmt.Errorf("password_policy.min_length (PASSWORD_MIN_LENGTH) must be positive, got %d", c.MinLength))
+	}
+	if c.MaxLength < c.MinLength || c.MaxLength > bcryptMaxPasswordBytes {
+		errs = append(errs, fmt.Errorf("password_policy.max_length (PASSWORD_MAX_LENGTH) must be between password_policy.min_length and %d, got %d", bcryptMaxPasswordBytes, c.MaxLength))
+	}
+	if c.BreachedPasswordMinCount < 1 {
+		errs = append(errs, fmt.Errorf("password_policy.breached_password_min_count (BREACHED_PASSWORD_MIN_COUNT) must be positive, got %d", c.BreachedPasswordMinCount))
+	}
+	return errs
+}
+
+// validate checks the parameters against what the algorithms accept, so they can be converted to the
+// hashers' unsigned types without wrapping around.
+func (c PasswordHashingConfig) validate() []error {
+	var errs []error
+	switch c.Algorithm {
+	case PasswordHashArgon2id, PasswordHashBcrypt:
+	default:
+		errs = append(errs, fmt.Errorf("password_hashing.algorithm (PASSWORD_HASH_ALGORITHM) must be argon2id or bcrypt, got %q", c.Algorithm))
+	}
+	if c.Argon2Parallelism < 1 || c.Argon2Parallelism > math.MaxUint8 {
+		errs = append(errs, fmt.Errorf("password_hashing.argon2_parallelism (PASSWORD_ARGON2_PARALLELISM) must be between 1 and %d, got %d", math.MaxUint8, c.Argon2Parallelism))
+	} else if minMemory := 8 * c.Argon2Parallelism; c.Argon2MemoryKiB < minMemory || int64(c.Argon2MemoryKiB) > math.MaxUint32 {
+		// Argon2 needs at least 8 KiB per lane
+		errs = append(errs, fmt.Errorf("password_hashing.argon2_memory_kib (PASSWORD_ARGON2_MEMORY_KIB) must be between %d and %d, got %d", minMemory, uint32(math.MaxUint32), c.Argon2MemoryKiB))
+	}
+	if c.Argon2Iterations < 1 || int64(c.Argon2Iterations) > math.MaxUint32 {
+		errs = append(errs, fmt.Errorf("password_hashing.argon2_iterations (PASSWORD_ARGON2_ITERATIONS) must be between 1 and %d, got %d", uint32(math.MaxUint32), c.Argon2Iterations))
+	}
+	if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+		errs = append(errs, fmt.Errorf("password_hashing.bcrypt_cost (PASSWORD_BCRYPT_COST) must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, c.BcryptCost))
+	}
+	return errs
+}
@@ -0,0 +1,375 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+	repository_token "organization_management/pkg/database/redis/repository"
+	util "organization_management/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Ways account deletion handles organizations where the user is the only Founder.
+const (
+	soleFounderTransfer = "transfer"
+	soleFounderDelete   = "delete"
+)
+
+// DeleteAccountInput represents the input data for deleting the current account
+type DeleteAccountInput struct {
+	ConfirmEmail string `json:"confirm_email" binding:"required"`
+}
+
+// PasswordResetInput represents the input data for redeeming a password reset token
+type PasswordResetInput struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ChangePasswordInput represents the input data for changing the current user's password
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// ResetPassword sets a new password with a reset token handed out by an administrator.
+func (h *Handler) ResetPassword() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		var input PasswordResetInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		user, err := h.Store.GetUserByPasswordResetHash(ctx, util.HashPasswordResetToken(input.Token))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve password reset"})
+			return
+		}
+		if user == nil || !time.Now().Before(user.PasswordReset.ExpiresAt) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+
+		if !h.enforcePasswordPolicy(c, input.Password, user.Email, user.Name) {
+			return
+		}
+
+		user.Password = input.Password
+		if err := h.hashUserPassword(ctx, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+		if err := h.Store.UpdateUserPassword(ctx, user.Id, user.Password); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully, you can sign in again"})
+	}
+}
+
+// ChangeMyPassword sets a new password for the current user after checking the current one. Accounts that
+// only signed in through an identity provider so far can set a first password without one. All sessions
+// are signed out, so the user signs in again with the new password.
+func (h *Handler) ChangeMyPassword() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		user := h.currentAccount(ctx, c)
+		if user == nil {
+			return
+		}
+
+		var input ChangePasswordInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if user.Password != "" {
+			if err := h.verifyPassword(ctx, input.CurrentPassword, user.Password); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Current password is incorrect"})
+				return
+			}
+		}
+		if !h.enforcePasswordPolicy(c, input.NewPassword, user.Email, user.Name) {
+			return
+		}
+
+		user.Password = input.NewPassword
+		if err := h.hashUserPassword(ctx, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+		if err := h.Store.UpdateUserPassword(ctx, user.Id, user.Password); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+			return
+		}
+		tokenRepo := repository_token.NewTokenRepository(h.Redis)
+		if err := tokenRepo.RevokeAllTokens(ctx, user.Id.Hex(), util.MaxTokenLifespan()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign out sessions"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully, please sign in again"})
+	}
+}
+
+// enforcePasswordPolicy checks a new password against the password policy. It writes a response listing
+// every broken rule and returns false when the password is refused.
+func (h *Handler) enforcePasswordPolicy(c *gin.Context, password, email, name string) bool {
+	violations, err := h.PasswordPolicy.Check(password, email, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check password"})
+		return false
+	}
+	if len(violations) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "Password doesn't meet the password policy",
+			"violations": violations,
+		})
+		return false
+	}
+	return true
+}
+
+// ExportMyData returns everything stored about the current user as a downloadable JSON document.
+func (h *Handler) ExportMyData() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 30*time.Second)
+		defer cancel()
+
+		user := h.currentAccount(ctx, c)
+		if user == nil {
+			return
+		}
+		userID := user.Id.Hex()
+
+		orgs, err := h.Store.GetOrganizationsByMemberEmail(ctx, user.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organizations"})
+			return
+		}
+		memberships := []gin.H{}
+		for _, org := range orgs {
+			if member := org.FindMember(user.Email); member != nil {
+				memberships = append(memberships, gin.H{
+					"organization_id": org.OrganizationId,
+					"name":            org.Name,
+					"access_level":    member.AccessLevel,
+					"member_name":     member.Name,
+				})
+			}
+		}
+
+		apiKeys, err := h.Store.GetAPIKeysByUserID(ctx, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API keys"})
+			return
+		}
+		joinRequests, err := h.Store.GetJoinRequestsByUserID(ctx, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve join requests"})
+			return
+		}
+		inviteLinks, err := h.Store.GetInviteLinksByCreator(ctx, user.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invite links"})
+			return
+		}
+
+		auditEvents, _, err := h.Store.FindAuditEvents(ctx, bson.M{"$or": bson.A{
+			bson.M{"actor_id": userID},
+			bson.M{"target_type": model.AuditTargetUser, "target_id": userID},
+		}}, 0, 0)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit events"})
+			return
+		}
+
+		// The refresh token itself is a credential, so only describe the session it belongs to
+		sessions := []gin.H{}
+		tokenRepo := repository_token.NewTokenRepository(h.Redis)
+		refreshToken, err := tokenRepo.FindRefreshToken(ctx, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sessions"})
+			return
+		}
+		if refreshToken != "" {
+			if claims, err := h.Tokens.VerifyRefreshToken(refreshToken); err == nil {
+				sessions = append(sessions, gin.H{
+					"type":       util.TokenTypeRefresh,
+					"issued_at":  claims.IssuedAt.Time,
+					"expires_at": claims.ExpiresAt.Time,
+				})
+			}
+		}
+
+		identities := []gin.H{}
+		for _, identity := range user.Identities {
+			identities = append(identities, gin.H{"provider": identity.Provider, "subject": identity.Subject})
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="account-`+userID+`.json"`)
+		c.JSON(http.StatusOK, gin.H{
+			"exported_at": time.Now().UTC(),
+			"profile": gin.H{
+				"id":                   userID,
+				"name":                 user.Name,
+				"email":                user.Email,
+				"external_id":          user.ExternalId,
+				"status":               user.AccountStatus(),
+				"has_password":         user.Password != "",
+				"federated_identities": identities,
+			},
+			"memberships":          memberships,
+			"api_keys":             apiKeys,
+			"join_requests":        joinRequests,
+			"invite_links_created": inviteLinks,
+			"sessions":             sessions,
+			"audit_events":         auditEvents,
+		})
+	}
+}
+
+// DeleteMyAccount erases the current user: their memberships, credentials, requests and the account itself.
+// Organizations where they are the only Founder must be handled explicitly with sole_founder=transfer, which
+// promotes another admin or member (deleting the organization if nobody is left), or sole_founder=delete.
+func (h *Handler) DeleteMyAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 30*time.Second)
+		defer cancel()
+
+		user := h.currentAccount(ctx, c)
+		if user == nil {
+			return
+		}
+
+		var input DeleteAccountInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !strings.EqualFold(input.ConfirmEmail, user.Email) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "confirm_email must match the account's email"})
+			return
+		}
+
+		mode := c.Query("sole_founder")
+		if mode != "" && mode != soleFounderTransfer && mode != soleFounderDelete {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sole_founder must be transfer or delete"})
+			return
+		}
+
+		orgs, err := h.Store.GetOrganizationsByMemberEmail(ctx, user.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organizations"})
+			return
+		}
+		var founded []model.Organization
+		for _, org := range orgs {
+			if org.IsSoleFounder(user.Email) {
+				founded = append(founded, org)
+			}
+		}
+		if len(founded) > 0 && mode == "" {
+			blocking := []gin.H{}
+			for _, org := range founded {
+				blocking = append(blocking, gin.H{"organization_id": org.OrganizationId, "name": org.Name})
+			}
+			c.JSON(http.StatusConflict, gin.H{
+				"error":         "You are the only Founder of these organizations, retry with sole_founder=transfer or sole_founder=delete",
+				"organizations": blocking,
+			})
+			return
+		}
+
+		transferred := []string{}
+		deleted := []string{}
+		for i := range founded {
+			org := &founded[i]
+			successor := org.Successor(user.Email)
+			if mode == soleFounderDelete || successor == nil {
+				if err := h.deleteOrganization(ctx, org.OrganizationId); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organization " + org.OrganizationId})
+					return
+				}
+				deleted = append(deleted, org.OrganizationId)
+				continue
+			}
+			if _, err := h.Store.SetMemberAccessLevel(ctx, org.OrganizationId, successor.UserEmail, model.AccessLevelFounder); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer organization " + org.OrganizationId})
+				return
+			}
+			transferred = append(transferred, org.OrganizationId)
+		}
+
+		if err := h.eraseAccount(ctx, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":                   "Account deleted successfully",
+			"transferred_organizations": transferred,
+			"deleted_organizations":     deleted,
+		})
+	}
+}
+
+// eraseAccount revokes the user's access and removes everything that identifies them.
+func (h *Handler) eraseAccount(ctx context.Context, user *model.User) error {
+	userID := user.Id.Hex()
+	if err := h.revokeUserAccess(ctx, userID); err != nil {
+		return err
+	}
+	if err := h.Store.RemoveMemberFromAllOrganizations(ctx, user.Email); err != nil {
+		return err
+	}
+	if err := h.Store.DeleteAPIKeysByUserID(ctx, userID); err != nil {
+		return err
+	}
+	if err := h.Store.DeleteJoinRequestsByUserID(ctx, userID); err != nil {
+		return err
+	}
+	if err := h.Store.ClearJoinRequestDecider(ctx, user.Email); err != nil {
+		return err
+	}
+	if err := h.Store.ClearInviteLinkCreator(ctx, user.Email); err != nil {
+		return err
+	}
+	attemptRepo := repository_token.NewLoginAttemptRepository(h.Redis)
+	if err := attemptRepo.Reset(ctx, "account:" + strings.ToLower(user.Email)); err != nil {
+		return err
+	}
+	return h.Store.DeleteUser(ctx, user.Id)
+}
+
+// currentAccount loads the user behind the request's access token. API keys and service accounts can't
+// act on the account itself. It writes the error response and returns nil when there is no such user.
+func (h *Handler) currentAccount(ctx context.Context, c *gin.Context) *model.User {
+	claims, err := util.ExtractClaims(c)
+	if err != nil || claims.TokenType != util.TokenTypeAccess {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This requires signing in with your password or identity provider"})
+		return nil
+	}
+	user, err := h.Store.GetUserByID(ctx, claims.UserID())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the current user"})
+		return nil
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil
+	}
+	return user
+}
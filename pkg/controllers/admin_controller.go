@@ -0,0 +1,496 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+	repository "organization_management/pkg/database/mongodb/repository"
+	repository_token "organization_management/pkg/database/redis/repository"
+	util "organization_management/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// PlatformRoleInput represents the input data for changing a user's platform role
+type PlatformRoleInput struct {
+	Role *string `json:"role" binding:"required"`
+}
+
+// ImpersonationInput represents the input data for impersonating a user
+type ImpersonationInput struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// TransferOwnershipInput represents the input data for forcibly transferring an organization
+type TransferOwnershipInput struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// UnlockUserAccount clears the failed sign-in history and lockout of an account.
+func (h *Handler) UnlockUserAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		var input struct {
+			Email string `json:"email" binding:"required"`
+		}
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !h.recordAuditEvent(ctx, c, model.AuditUserUnlocked, model.AuditTargetUser, strings.ToLower(input.Email), nil) {
+			return
+		}
+		attemptRepo := repository_token.NewLoginAttemptRepository(h.Redis)
+		if err := attemptRepo.Reset(ctx, "account:" + strings.ToLower(input.Email)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock account"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Account unlocked successfully"})
+	}
+}
+
+// AdminListUsers lists every user, optionally filtered by a name or email search in q and by status
+// (active, suspended or disabled).
+func (h *Handler) AdminListUsers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		offset, limit, ok := parsePagination(c, defaultAdminPageSize, maxAdminPageSize)
+		if !ok {
+			return
+		}
+
+		filter := bson.M{}
+		if query := c.Query("q"); query != "" {
+			pattern := bson.M{"$regex": "(?i)" + regexp.QuoteMeta(query)}
+			filter["$or"] = bson.A{bson.M{"name": pattern}, bson.M{"email": pattern}}
+		}
+		switch status := c.Query("status"); status {
+		case "":
+		case model.UserStatusActive:
+			filter["status"] = bson.M{"$nin": bson.A{model.UserStatusSuspended, model.UserStatusDisabled}}
+		case model.UserStatusSuspended, model.UserStatusDisabled:
+			filter["status"] = status
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active, suspended or disabled"})
+			return
+		}
+
+		users, total, err := h.Store.FindUsers(ctx, filter, offset, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+			return
+		}
+
+		userList := make([]gin.H, 0, len(users))
+		for i := range users {
+			userList = append(userList, adminUserResource(&users[i]))
+		}
+		c.JSON(http.StatusOK, gin.H{"users": userList, "total": total})
+	}
+}
+
+// AdminGetUser returns a user along with their organization memberships.
+func (h *Handler) AdminGetUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		user := h.loadAdminUser(ctx, c)
+		if user == nil {
+			return
+		}
+
+		orgs, err := h.Store.GetOrganizationsByMemberEmail(ctx, user.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organizations"})
+			return
+		}
+		memberships := []gin.H{}
+		for _, org := range orgs {
+			if member := org.FindMember(user.Email); member != nil {
+				memberships = append(memberships, gin.H{
+					"organization_id": org.OrganizationId,
+					"name":            org.Name,
+					"access_level":    member.AccessLevel,
+				})
+			}
+		}
+
+		resource := adminUserResource(user)
+		resource["memberships"] = memberships
+		c.JSON(http.StatusOK, resource)
+	}
+}
+
+// AdminSuspendUser puts a temporary hold on an account: it is signed out everywhere and can't sign in,
+// while its memberships are kept so it can be reactivated as it was.
+func (h *Handler) AdminSuspendUser() gin.HandlerFunc {
+	return h.adminChangeUserStatus(model.UserStatusSuspended, model.AuditUserSuspended)
+}
+
+// AdminDisableUser locks an account out for good, for example when its owner leaves. It is signed out
+// everywhere and can't sign in. Memberships are kept so the account can still be reactivated.
+func (h *Handler) AdminDisableUser() gin.HandlerFunc {
+	return h.adminChangeUserStatus(model.UserStatusDisabled, model.AuditUserDisabled)
+}
+
+// AdminEnableUser lets a suspended or disabled account sign in again.
+func (h *Handler) AdminEnableUser() gin.HandlerFunc {
+	return h.adminChangeUserStatus(model.UserStatusActive, model.AuditUserEnabled)
+}
+
+func (h *Handler) adminChangeUserStatus(status, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		user := h.loadAdminUser(ctx, c)
+		if user == nil || isCurrentUser(c, user) {
+			return
+		}
+
+		if !h.recordAuditEvent(ctx, c, action, model.AuditTargetUser, user.Id.Hex(), gin.H{
+			"email":           user.Email,
+			"previous_status": user.AccountStatus(),
+			"status":          status,
+		}) {
+			return
+		}
+		if err := h.setUserStatus(ctx, user, status); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user status"})
+			return
+		}
+
+		c.JSON(http.StatusOK, adminUserResource(user))
+	}
+}
+
+// AdminForcePasswordReset signs the user out everywhere and blocks password sign-in until a new password
+// is set with the returned reset token. The token is shown only once and should reach the user out of band.
+func (h *Handler) AdminForcePasswordReset() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		user := h.loadAdminUser(ctx, c)
+		if user == nil {
+			return
+		}
+
+		token, hashedToken, err := util.GeneratePasswordResetToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate reset token"})
+			return
+		}
+		if !h.recordAuditEvent(ctx, c, model.AuditUserPasswordReset, model.AuditTargetUser, user.Id.Hex(), gin.H{"email": user.Email}) {
+			return
+		}
+		reset := model.PasswordReset{HashedToken: hashedToken, ExpiresAt: time.Now().UTC().Add(util.PasswordResetLifespan)}
+		if err := h.Store.SetPasswordReset(ctx, user.Id, reset); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save password reset"})
+			return
+		}
+		if err := h.revokeUserAccess(ctx, user.Id.Hex()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke user tokens"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"reset_token": token,
+			"expires_at":  reset.ExpiresAt,
+			"message":     "Store the reset token now, it won't be shown again",
+		})
+	}
+}
+
+// AdminSetPlatformRole grants or removes the platform admin role. Administrators can't change their own role.
+func (h *Handler) AdminSetPlatformRole() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		var input PlatformRoleInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !model.IsValidPlatformRole(*input.Role) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "role must be admin or empty"})
+			return
+		}
+
+		user := h.loadAdminUser(ctx, c)
+		if user == nil || isCurrentUser(c, user) {
+			return
+		}
+
+		if !h.recordAuditEvent(ctx, c, model.AuditUserPlatformRole, model.AuditTargetUser, user.Id.Hex(), gin.H{
+			"email":         user.Email,
+			"previous_role": user.PlatformRole,
+			"role":          *input.Role,
+		}) {
+			return
+		}
+		user.PlatformRole = *input.Role
+		if err := h.Store.UpdateUser(ctx, user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+			return
+		}
+
+		c.JSON(http.StatusOK, adminUserResource(user))
+	}
+}
+
+// AdminImpersonateUser issues a short-lived access token that acts as the user, so support can see what they
+// see. The token names the administrator in its act claim, every request made with it is audited, and it
+// can't be used for admin routes or for actions only the account owner may take.
+func (h *Handler) AdminImpersonateUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		var input ImpersonationInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		user := h.loadAdminUser(ctx, c)
+		if user == nil || isCurrentUser(c, user) {
+			return
+		}
+		if !user.IsActive() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only active users can be impersonated"})
+			return
+		}
+		if user.IsPlatformAdmin() || h.Admins.IsAdminEmail(user.Email) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Platform administrators can't be impersonated"})
+			return
+		}
+
+		claims, err := util.ExtractClaims(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+		lifespan := h.Admins.ImpersonationLifespan
+		actor := util.TokenActor{Subject: claims.UserID(), Email: claims.Email}
+		token, err := h.Tokens.GenerateImpersonationToken(user.Id.Hex(), user.Email, actor, lifespan)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+			return
+		}
+
+		// The token is only handed out once its issue is on record
+		if !h.recordAuditEvent(ctx, c, model.AuditUserImpersonated, model.AuditTargetUser, user.Id.Hex(), gin.H{
+			"email":      user.Email,
+			"reason":     input.Reason,
+			"expires_in": int(lifespan.Seconds()),
+		}) {
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"access_token": token,
+			"token_type":   "Bearer",
+			"expires_in":   int(lifespan.Seconds()),
+			"user":         adminUserResource(user),
+		})
+	}
+}
+
+// AdminListOrganizations lists every organization with its statistics, optionally filtered by a name search in q.
+func (h *Handler) AdminListOrganizations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 30*time.Second)
+		defer cancel()
+
+		offset, limit, ok := parsePagination(c, defaultAdminPageSize, maxAdminPageSize)
+		if !ok {
+			return
+		}
+
+		filter := bson.M{}
+		if query := c.Query("q"); query != "" {
+			filter["name"] = bson.M{"$regex": "(?i)" + regexp.QuoteMeta(query)}
+		}
+
+		orgs, total, err := h.Store.FindOrganizationsWithCounts(ctx, filter, offset, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organizations"})
+			return
+		}
+
+		orgList := make([]gin.H, 0, len(orgs))
+		for i := range orgs {
+			orgList = append(orgList, gin.H{
+				"organization_id": orgs[i].OrganizationId,
+				"name":            orgs[i].Name,
+				"description":     orgs[i].Description,
+				"discoverable":    orgs[i].Discoverable,
+				"stats":           organizationStats(&orgs[i]),
+			})
+		}
+		c.JSON(http.StatusOK, gin.H{"organizations": orgList, "total": total})
+	}
+}
+
+// AdminTransferOwnership makes the user with the given email the organization's only Founder, adding them
+// as a member if needed. Previous Founders stay on as admins.
+func (h *Handler) AdminTransferOwnership() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		var input TransferOwnershipInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		orgID := c.Param("organization_id")
+		org, err := h.Store.GetOrganizationByID(ctx, orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+
+		user, err := h.Store.GetUserByEmail(ctx, input.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+			return
+		}
+		if user == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if !user.IsActive() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only an active user can own an organization"})
+			return
+		}
+
+		previousFounders := []string{}
+		for _, member := range org.OrganizationMembers {
+			if member.AccessLevel == model.AccessLevelFounder && !strings.EqualFold(member.UserEmail, user.Email) {
+				previousFounders = append(previousFounders, member.UserEmail)
+			}
+		}
+
+		if !h.recordAuditEvent(ctx, c, model.AuditOrganizationTransfer, model.AuditTargetOrganization, org.OrganizationId, gin.H{
+			"founder":           user.Email,
+			"previous_founders": previousFounders,
+		}) {
+			return
+		}
+		founder := model.OrganizationMember{
+			Name:      user.Name,
+			UserEmail: user.Email,
+			Type:      model.MemberTypeUser,
+		}
+		if err := h.Store.TransferOrganizationOwnership(ctx, org.OrganizationId, founder); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":           "Ownership transferred successfully",
+			"organization_id":   org.OrganizationId,
+			"founder":           user.Email,
+			"previous_founders": previousFounders,
+		})
+	}
+}
+
+// organizationStats summarizes an organization's members and the records that belong to it.
+func organizationStats(org *repository.OrganizationWithCounts) gin.H {
+	counts := map[string]int{}
+	founders := []string{}
+	for _, member := range org.OrganizationMembers {
+		if member.Type == model.MemberTypeServiceAccount {
+			counts["service_accounts"]++
+			continue
+		}
+		counts[member.AccessLevel]++
+		if member.AccessLevel == model.AccessLevelFounder {
+			founders = append(founders, member.UserEmail)
+		}
+	}
+
+	return gin.H{
+		"member_count":          len(org.OrganizationMembers) - counts["service_accounts"],
+		"founders":              founders,
+		"admin_count":           counts[model.AccessLevelAdmin],
+		"service_account_count": counts["service_accounts"],
+		"domain_count":          org.DomainCount,
+		"pending_join_requests": org.PendingJoinRequests,
+		"active_invite_links":   org.ActiveInviteLinks,
+	}
+}
+
+// setUserStatus saves the account state. Leaving the active state signs the user out everywhere.
+func (h *Handler) setUserStatus(ctx context.Context, user *model.User, status string) error {
+	user.Status = status
+	if err := h.Store.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+	if status == model.UserStatusActive {
+		return nil
+	}
+	return h.revokeUserAccess(ctx, user.Id.Hex())
+}
+
+// loadAdminUser loads the user named by the user_id path parameter. It writes the error response and
+// returns nil when there is no such user.
+func (h *Handler) loadAdminUser(ctx context.Context, c *gin.Context) *model.User {
+	user, err := h.Store.GetUserByID(ctx, c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		return nil
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil
+	}
+	return user
+}
+
+// isCurrentUser refuses, with an error response, actions administrators would take on their own account.
+func isCurrentUser(c *gin.Context, user *model.User) bool {
+	claims, err := util.ExtractClaims(c)
+	if err == nil && claims.UserID() == user.Id.Hex() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Administrators can't do this to their own account"})
+		return true
+	}
+	return false
+}
+
+func adminUserResource(user *model.User) gin.H {
+	providers := []string{}
+	for _, identity := range user.Identities {
+		providers = append(providers, identity.Provider)
+	}
+	return gin.H{
+		"id":                     user.Id.Hex(),
+		"name":                   user.Name,
+		"email":                  user.Email,
+		"status":                 user.AccountStatus(),
+		"platform_role":          user.PlatformRole,
+		"has_password":           user.Password != "",
+		"password_reset_pending": user.PasswordReset != nil,
+		"identity_providers":     providers,
+	}
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+	util "organization_management/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAPIKeyLifespanDays = 90
+	maxAPIKeyLifespanDays     = 365
+)
+
+// CreateAPIKeyInput represents the input data for creating an API key
+type CreateAPIKeyInput struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+// CreateAPIKey creates a personal API key for the current user. The key is only returned once.
+func (h *Handler) CreateAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		var input CreateAPIKeyInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		claims, err := util.ExtractClaims(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve current user"})
+			return
+		}
+
+		// A key can't grant more than the credential used to create it
+		if len(input.Scopes) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "At least one scope is required"})
+			return
+		}
+		for _, scope := range input.Scopes {
+			if !util.IsValidScope(scope) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope: " + scope})
+				return
+			}
+			if !claims.HasScope(scope) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Cannot grant scope: " + scope})
+				return
+			}
+		}
+
+		if input.ExpiresInDays == 0 {
+			input.ExpiresInDays = defaultAPIKeyLifespanDays
+		}
+		if input.ExpiresInDays < 0 || input.ExpiresInDays > maxAPIKeyLifespanDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_days must be between 1 and 365"})
+			return
+		}
+
+		key, keyID, hashedKey, err := util.GenerateAPIKey()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+			return
+		}
+
+		now := time.Now()
+		apiKey := model.APIKey{
+			KeyId:     keyID,
+			UserId:    claims.UserID(),
+			UserEmail: claims.Email,
+			Name:      input.Name,
+			Scopes:    input.Scopes,
+			HashedKey: hashedKey,
+			CreatedAt: now,
+			ExpiresAt: util.APIKeyExpiry(claims, now.AddDate(0, 0, input.ExpiresInDays)),
+		}
+		if err := h.Store.InsertAPIKey(ctx, apiKey); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save API key"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"key_id":     apiKey.KeyId,
+			"name":       apiKey.Name,
+			"scopes":     apiKey.Scopes,
+			"expires_at": apiKey.ExpiresAt,
+			"api_key":    key,
+			"message":    "Store this key now, it won't be shown again",
+		})
+	}
+}
+
+// ListAPIKeys lists the current user's API keys without their secrets.
+func (h *Handler) ListAPIKeys() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		claims, err := util.ExtractClaims(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve current user"})
+			return
+		}
+
+		keys, err := h.Store.GetAPIKeysByUserID(ctx, claims.UserID())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API keys"})
+			return
+		}
+
+		c.JSON(http.StatusOK, keys)
+	}
+}
+
+// RevokeAPIKey revokes one of the current user's API keys.
+func (h *Handler) RevokeAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		claims, err := util.ExtractClaims(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve current user"})
+			return
+		}
+
+		if err := h.Store.RevokeAPIKey(ctx, claims.UserID(), c.Param("key_id")); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+	}
+}
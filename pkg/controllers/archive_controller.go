@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"organization_management/pkg/archive"
+	model "organization_management/pkg/database/mongodb/models"
+	util "organization_management/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxArchiveBytes bounds the size of an uploaded organization archive.
+const maxArchiveBytes = 50 << 20
+
+// ExportOrganization downloads the organization and everything that belongs to it as a JSON archive.
+func (h *Handler) ExportOrganization() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 30*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		if h.authorizeOrganizationManager(ctx, c, orgID) == nil {
+			return
+		}
+
+		exported, err := archive.Export(ctx, h.Store, orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export organization"})
+			return
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="organization-`+orgID+`.json"`)
+		c.JSON(http.StatusOK, exported)
+	}
+}
+
+// ImportOrganization recreates an organization from an archive. on_conflict (fail, skip or rename) decides
+// what happens when an organization with the same name exists.
+func (h *Handler) ImportOrganization() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 60*time.Second)
+		defer cancel()
+
+		onConflict := c.DefaultQuery("on_conflict", util.ArchiveConflictFail)
+		if !util.ValidArchiveConflict(onConflict) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": util.ErrInvalidArchiveConflict.Error()})
+			return
+		}
+
+		archived, err := util.DecodeOrganizationArchive(http.MaxBytesReader(c.Writer, c.Request.Body, maxArchiveBytes))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := archive.Import(ctx, h.Store, archived, onConflict)
+		if errors.Is(err, archive.ErrOrganizationExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import organization: " + err.Error()})
+			return
+		}
+
+		status := http.StatusCreated
+		if result.Skipped {
+			status = http.StatusOK
+		} else if !h.recordAuditEvent(ctx, c, model.AuditOrganizationImported, model.AuditTargetOrganization, result.OrganizationId, gin.H{
+			"name":                   result.Name,
+			"source_organization_id": result.SourceOrganizationId,
+		}) {
+			// The new organization's id is only known now, so an import that can't be recorded is undone
+			archive.Rollback(ctx, h.Store, result.OrganizationId)
+			return
+		}
+		c.JSON(status, result)
+	}
+}
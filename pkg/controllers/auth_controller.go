@@ -2,29 +2,34 @@ package controller
 
 import (
     "context"
+    "log"
+    "math"
     "net/http"
-    "time"
-    "os"
     "strconv"
-    "fmt"
+    "strings"
+    "time"
 
     model "organization_management/pkg/database/mongodb/models"
-    repository "organization_management/pkg/database/mongodb/repository"
     repository_token "organization_management/pkg/database/redis/repository"
+	"organization_management/pkg/metrics"
+	"organization_management/pkg/tracing"
 	util "organization_management/pkg/utils"
-    redis "organization_management/pkg/database/redis"
 
     "github.com/gin-gonic/gin"
     "github.com/go-playground/validator/v10"
-    "github.com/dgrijalva/jwt-go"
-
 )
 
 var validate = validator.New()
 
-func RegisterUser() gin.HandlerFunc {
+// requestContext bounds a handler's storage calls by timeout and carries the request's trace into them.
+// It is not cancelled when the client disconnects, so a multi-step change is never left half done.
+func requestContext(c *gin.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+    return context.WithTimeout(context.WithoutCancel(c.Request.Context()), timeout)
+}
+
+func (h *Handler) RegisterUser() gin.HandlerFunc {
     return func(c *gin.Context) {
-        ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        ctx, cancel := requestContext(c, 10*time.Second)
         defer cancel()
 
         var user model.User
@@ -51,8 +56,13 @@ func RegisterUser() gin.HandlerFunc {
             return
         }
 
+        // Check the password against the password policy
+        if !h.enforcePasswordPolicy(c, user.Password, user.Email, user.Name) {
+            return
+        }
+
         // Check if the user already exists
-        existingUser, err := repository.GetUserByEmail(ctx, user.Email)
+        existingUser, err := h.Store.GetUserByEmail(ctx, user.Email)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check user existence"})
             return
@@ -63,7 +73,7 @@ func RegisterUser() gin.HandlerFunc {
         }
 
         // Hash the user's password before saving it
-        if err := user.HashPassword(); err != nil {
+        if err := h.hashUserPassword(ctx, &user); err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{
                 "error": err.Error(),
             })
@@ -71,7 +81,7 @@ func RegisterUser() gin.HandlerFunc {
         }
 
         // Insert the user into the database
-        result, err := repository.InsertUser(ctx, user)
+        result, err := h.Store.InsertUser(ctx, user)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{
                 "error": err.Error(),
@@ -79,6 +89,8 @@ func RegisterUser() gin.HandlerFunc {
             return
         }
 
+        metrics.RecordSignup()
+
         c.JSON(http.StatusCreated, gin.H{
             "status":  http.StatusCreated,
             "message": "success",
@@ -94,9 +106,9 @@ type LoginInput struct {
 }
 
 // LoginUser handles the login request
-func LoginUser() gin.HandlerFunc {
+func (h *Handler) LoginUser() gin.HandlerFunc {
 	return func(c *gin.Context) {
-        ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        ctx, cancel := requestContext(c, 10*time.Second)
         defer cancel()
 
         var input LoginInput
@@ -117,31 +129,71 @@ func LoginUser() gin.HandlerFunc {
             return
         }
 
+        // Refuse the attempt while the account or the client address is blocked
+        attemptRepo := repository_token.NewLoginAttemptRepository(h.Redis)
+        accountKey := "account:" + strings.ToLower(input.Email)
+        ipKey := "ip:" + c.ClientIP()
+        retryAfter, err := loginBlockedFor(ctx, attemptRepo, accountKey, ipKey)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check sign-in attempts"})
+            return
+        }
+        if retryAfter > 0 {
+            metrics.RecordLogin(metrics.LoginMethodPassword, false)
+            respondTooManyAttempts(c, retryAfter)
+            return
+        }
+
         // Check if user exists
-        user, err := repository.GetUserByEmail(ctx, input.Email)
+        user, err := h.Store.GetUserByEmail(ctx, input.Email)
         if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{
-                "error": "User with this email does not exist",
-            })
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
             return
         }
 
-        // Verify password
-        if err := user.VerifyPassword(input.Password, user.Password); err != nil {
+        // Verify password, comparing against a dummy hash for unknown emails so both cases take the same time
+        hashedPassword := h.dummyPasswordHash()
+        if user != nil {
+            hashedPassword = user.Password
+        }
+        if err := h.verifyPassword(ctx, input.Password, hashedPassword); err != nil || user == nil {
+            if err := registerLoginFailure(ctx, attemptRepo, h.LoginPolicy, accountKey, ipKey); err != nil {
+                c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record sign-in attempt"})
+                return
+            }
+            metrics.RecordLogin(metrics.LoginMethodPassword, false)
             c.JSON(http.StatusBadRequest, gin.H{
-                "error": "Password is incorrect",
+                "error": invalidCredentialsMessage,
             })
             return
         }
 
-        // Convert ObjectID to timestamp
-        timestamp := user.Id.Timestamp()
-        // Get the seconds part of the timestamp
-        seconds := timestamp.Unix()
-        // Convert seconds to uint
-        userID := uint(seconds)
+        // A successful sign-in clears the account's failure history
+        if err := attemptRepo.Reset(ctx, accountKey); err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset sign-in attempts"})
+            return
+        }
 
-        token, refreshToken, err := util.GenerateToken(userID, user.Email)
+        // Suspended and disabled accounts can't sign in
+        if !user.IsActive() {
+            metrics.RecordLogin(metrics.LoginMethodPassword, false)
+            c.JSON(http.StatusForbidden, gin.H{"error": inactiveAccountMessage(user)})
+            return
+        }
+
+        // An administrator forced a password reset, so the old password no longer signs in
+        if user.PasswordReset != nil {
+            metrics.RecordLogin(metrics.LoginMethodPassword, false)
+            c.JSON(http.StatusForbidden, gin.H{"error": "Password reset required, use the reset token from your administrator"})
+            return
+        }
+
+        // Hashes made with an older algorithm or weaker parameters are replaced now that the password is known
+        h.upgradePasswordHash(ctx, user, input.Password)
+
+        userID := user.Id.Hex()
+
+        token, refreshToken, err := h.Tokens.GenerateToken(userID, user.Email)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{
                 "error": "Error generating tokens",
@@ -150,15 +202,15 @@ func LoginUser() gin.HandlerFunc {
         }
 
         // Save refresh token in Redis
-		tokenRepo := repository_token.NewTokenRepository(redis.RedisClient)
-        // Convert userID to string
-        userIDStr := strconv.FormatUint(uint64(userID), 10)
-		err = tokenRepo.SaveRefreshToken(userIDStr, refreshToken)
+		tokenRepo := repository_token.NewTokenRepository(h.Redis)
+		err = tokenRepo.SaveRefreshToken(ctx, userID, refreshToken)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save refresh token"})
 			return
 		}
 
+        metrics.RecordLogin(metrics.LoginMethodPassword, true)
+
         // Respond with tokens and message
         c.JSON(http.StatusOK, gin.H{
             "access_token":  token,
@@ -168,9 +220,132 @@ func LoginUser() gin.HandlerFunc {
     }
 }
 
+// inactiveAccountMessage explains why a suspended or disabled user can't sign in.
+func inactiveAccountMessage(user *model.User) string {
+    if user.AccountStatus() == model.UserStatusSuspended {
+        return "Account is suspended"
+    }
+    return "Account is disabled"
+}
+
+// invalidCredentialsMessage is returned for every failed sign-in so responses don't reveal which emails are registered.
+const invalidCredentialsMessage = "Invalid email or password"
+
+// legacyDummyPasswordHash is a bcrypt hash used when the configured hasher can't produce a dummy hash.
+const legacyDummyPasswordHash = "$2a$10$owUDXA7tsXoDxyMQhVUOc.DKjiU6gHAbV4LvkOkwXLqjv5ZeVwUeW"
+
+// dummyPasswordHash returns a hash from the configured hasher to compare against when the email is unknown,
+// so that both cases cost the same.
+func (h *Handler) dummyPasswordHash() string {
+    h.dummyHashOnce.Do(func() {
+        hashed, err := h.PasswordHashing.Hash("dummy password for unknown accounts")
+        if err != nil {
+            hashed = legacyDummyPasswordHash
+        }
+        h.dummyHash = hashed
+    })
+    return h.dummyHash
+}
+
+// verifyPassword checks a password in its own span, since hashing is usually the slowest part of a sign-in.
+func (h *Handler) verifyPassword(ctx context.Context, password, hashedPassword string) error {
+    _, span := tracing.Tracer().Start(ctx, "password.verify")
+    defer span.End()
+    return h.PasswordHashing.Verify(password, hashedPassword)
+}
+
+// hashUserPassword replaces the user's plain text password with its hash, in its own span.
+func (h *Handler) hashUserPassword(ctx context.Context, user *model.User) error {
+    _, span := tracing.Tracer().Start(ctx, "password.hash")
+    defer span.End()
+    return user.HashPassword(h.PasswordHashing)
+}
+
+// upgradePasswordHash re-hashes a password that was just verified when its stored hash uses another
+// algorithm or outdated parameters. Failures are logged since the sign-in itself succeeded.
+func (h *Handler) upgradePasswordHash(ctx context.Context, user *model.User, password string) {
+    if !h.PasswordHashing.NeedsRehash(user.Password) {
+        return
+    }
+    _, span := tracing.Tracer().Start(ctx, "password.rehash")
+    hashed, err := h.PasswordHashing.Hash(password)
+    span.End()
+    if err == nil {
+        err = h.Store.ReplacePasswordHash(ctx, user.Id, user.Password, hashed)
+    }
+    if err != nil {
+        log.Printf("upgrading password hash of user %s: %v", user.Id.Hex(), err)
+        return
+    }
+    user.Password = hashed
+}
+
+// loginBlockedFor returns the longest remaining block among the given keys.
+func loginBlockedFor(ctx context.Context, attemptRepo *repository_token.LoginAttemptRepository, keys ...string) (time.Duration, error) {
+    var longest time.Duration
+    for _, key := range keys {
+        blockedFor, err := attemptRepo.BlockedFor(ctx, key)
+        if err != nil {
+            return 0, err
+        }
+        if blockedFor > longest {
+            longest = blockedFor
+        }
+    }
+    return longest, nil
+}
+
+// registerLoginFailure counts a failed attempt for the account and the client address,
+// holding back the next attempt progressively and locking out once the limits are reached.
+func registerLoginFailure(ctx context.Context, attemptRepo *repository_token.LoginAttemptRepository, policy util.LoginPolicy, accountKey, ipKey string) error {
+    accountFailures, err := attemptRepo.IncrementFailures(ctx, accountKey, policy.Window)
+    if err != nil {
+        return err
+    }
+    if accountFailures >= policy.MaxAccountAttempts {
+        err = attemptRepo.Block(ctx, accountKey, policy.Lockout)
+    } else if delay := policy.DelayAfter(accountFailures); delay > 0 {
+        err = attemptRepo.Block(ctx, accountKey, delay)
+    }
+    if err != nil {
+        return err
+    }
+
+    ipFailures, err := attemptRepo.IncrementFailures(ctx, ipKey, policy.Window)
+    if err != nil {
+        return err
+    }
+    if ipFailures >= policy.MaxIPAttempts {
+        return attemptRepo.Block(ctx, ipKey, policy.Lockout)
+    }
+    return nil
+}
+
+func respondTooManyAttempts(c *gin.Context, retryAfter time.Duration) {
+    seconds := int(math.Ceil(retryAfter.Seconds()))
+    c.Header("Retry-After", strconv.Itoa(seconds))
+    c.JSON(http.StatusTooManyRequests, gin.H{
+        "error":       "Too many failed sign-in attempts, please try again later",
+        "retry_after": seconds,
+    })
+}
+
+// revokeUserAccess signs the user out everywhere: their refresh token, every access token issued so far
+// and their API keys stop working.
+func (h *Handler) revokeUserAccess(ctx context.Context, userID string) error {
+    tokenRepo := repository_token.NewTokenRepository(h.Redis)
+    if err := tokenRepo.RevokeAllTokens(ctx, userID, util.MaxTokenLifespan()); err != nil {
+        return err
+    }
+    return h.Store.RevokeAPIKeysByUserID(ctx, userID)
+}
+
 // RefreshToken handles the refresh token request
-func RefreshToken() gin.HandlerFunc {
+func (h *Handler) RefreshToken() gin.HandlerFunc {
     return func(c *gin.Context) {
+        ctx, cancel := requestContext(c, 10*time.Second)
+        defer cancel()
+
         var input struct {
             RefreshToken string `json:"refresh_token" binding:"required"`
         }
@@ -180,58 +355,62 @@ func RefreshToken() gin.HandlerFunc {
         }
 
         // Parse and validate the refresh token
-        token, err := jwt.Parse(input.RefreshToken, func(token *jwt.Token) (interface{}, error) {
-            if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-                return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-            }
-            return []byte(os.Getenv("API_SECRET")), nil
-        })
-        if err != nil || !token.Valid {
+        claims, err := h.Tokens.VerifyRefreshToken(input.RefreshToken)
+        if err != nil {
+            metrics.RecordTokenRefresh(false)
             c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid refresh token"})
             return
         }
+        userID := claims.UserID()
 
-        // Extract user ID from the refresh token claims
-        claims, ok := token.Claims.(jwt.MapClaims)
-        if !ok {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid refresh token claims"})
+        // Make sure the refresh token is the one currently issued to the user
+		tokenRepo := repository_token.NewTokenRepository(h.Redis)
+        storedToken, err := tokenRepo.GetRefreshToken(ctx, userID)
+        if err != nil || storedToken != input.RefreshToken {
+            metrics.RecordTokenRefresh(false)
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid refresh token"})
             return
         }
-        userID, ok := claims["user_id"].(float64)
-        if !ok {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID in refresh token"})
+
+        // Suspended and disabled accounts can't keep their session alive
+        user, err := h.Store.GetUserByID(ctx, userID)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+            return
+        }
+        if user == nil || !user.IsActive() {
+            message := "Account no longer exists"
+            if user != nil {
+                message = inactiveAccountMessage(user)
+            }
+            metrics.RecordTokenRefresh(false)
+            c.JSON(http.StatusForbidden, gin.H{"error": message})
             return
         }
 
         // Revoke the old refresh token
-		tokenRepo := repository_token.NewTokenRepository(redis.RedisClient)
-        // Convert userID to string
-        userIDStr := strconv.FormatUint(uint64(userID), 10)
-        err = tokenRepo.RevokeRefreshTokenWithId(userIDStr)
+        err = tokenRepo.RevokeRefreshTokenWithId(ctx, userID)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh token"})
             return
         }
 
         // Generate a new access token and refresh token
-        email, ok := claims["email"].(string)
-        if !ok {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email in refresh token"})
-            return
-        }
-        accessToken, refreshToken, err := util.GenerateToken(uint(userID), email)
+        accessToken, refreshToken, err := h.Tokens.GenerateToken(userID, claims.Email)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating access token"})
             return
         }
 
         // Save the new refresh token in Redis
-		err = tokenRepo.SaveRefreshToken(userIDStr, refreshToken)
+		err = tokenRepo.SaveRefreshToken(ctx, userID, refreshToken)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save refresh token"})
 			return
 		}
 
+        metrics.RecordTokenRefresh(true)
+
         // Respond with new access token and message
         c.JSON(http.StatusOK, gin.H{
             "access_token":  accessToken,
@@ -249,19 +428,29 @@ type RevokeRefreshTokenResponse struct {
     Message string `json:"message"`
 }
 
-func RevokeToken() gin.HandlerFunc {
+func (h *Handler) RevokeToken() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
 		var req RevokeRefreshTokenRequest
 	    if err := c.BindJSON(&req); err != nil {
 		    c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		    return
 	    }
 
+	    // Validate the refresh token before revoking it
+	    claims, err := h.Tokens.VerifyRefreshToken(req.RefreshToken)
+	    if err != nil {
+		    c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid refresh token"})
+		    return
+	    }
+
 	    // Initialize token repository with Redis client
-	    tokenRepo := repository_token.NewTokenRepository(redis.RedisClient)
+	    tokenRepo := repository_token.NewTokenRepository(h.Redis)
 
 	    // Revoke the refresh token
-	    err := tokenRepo.RevokeRefreshToken(req.RefreshToken)
+	    err = tokenRepo.RevokeRefreshToken(ctx, claims.UserID(), req.RefreshToken)
 	    if err != nil {
 	    	c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke refresh token"})
 	    	return
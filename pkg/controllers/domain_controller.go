@@ -0,0 +1,315 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+	repository "organization_management/pkg/database/mongodb/repository"
+	util "organization_management/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DomainInput represents the input data for claiming a domain or changing its settings
+type DomainInput struct {
+	Domain             string `json:"domain"`
+	AutoJoin           *bool  `json:"auto_join"`
+	DefaultAccessLevel string `json:"default_access_level"`
+}
+
+// validAutoJoinAccessLevel allows admin and member. Founders are never made by a matching email.
+func validAutoJoinAccessLevel(accessLevel string) bool {
+	return accessLevel == model.AccessLevelAdmin || accessLevel == model.AccessLevelMember
+}
+
+// ClaimOrganizationDomain starts the verification of an email domain for the organization.
+// The response tells the caller which TXT record to publish.
+func (h *Handler) ClaimOrganizationDomain() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		if h.authorizeOrganizationManager(ctx, c, orgID) == nil {
+			return
+		}
+
+		var input DomainInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		domain, err := util.NormalizeDomain(input.Domain)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "domain must be a valid domain name"})
+			return
+		}
+		if input.DefaultAccessLevel == "" {
+			input.DefaultAccessLevel = model.AccessLevelMember
+		}
+		if !validAutoJoinAccessLevel(input.DefaultAccessLevel) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "default_access_level must be admin or member"})
+			return
+		}
+
+		existing, err := h.Store.GetOrganizationDomain(ctx, orgID, domain)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve domain"})
+			return
+		}
+		if existing != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is already claimed by this organization"})
+			return
+		}
+		verified, err := h.Store.GetVerifiedOrganizationDomain(ctx, domain)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve domain"})
+			return
+		}
+		if verified != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is already verified by another organization"})
+			return
+		}
+
+		token, err := util.GenerateDomainVerificationToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate verification token"})
+			return
+		}
+		claim := model.OrganizationDomain{
+			OrganizationId:     orgID,
+			Domain:             domain,
+			VerificationToken:  token,
+			AutoJoin:           input.AutoJoin != nil && *input.AutoJoin,
+			DefaultAccessLevel: input.DefaultAccessLevel,
+			CreatedAt:          time.Now(),
+		}
+		if err := h.Store.InsertOrganizationDomain(ctx, claim); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save domain"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, domainResponse(&claim))
+	}
+}
+
+// ListOrganizationDomains lists the domains claimed by the organization.
+func (h *Handler) ListOrganizationDomains() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		if h.authorizeOrganizationManager(ctx, c, orgID) == nil {
+			return
+		}
+
+		domains, err := h.Store.GetOrganizationDomainsByOrganizationID(ctx, orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve domains"})
+			return
+		}
+		response := make([]gin.H, 0, len(domains))
+		for i := range domains {
+			response = append(response, domainResponse(&domains[i]))
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// VerifyOrganizationDomain checks the challenge TXT record and marks the domain as verified.
+func (h *Handler) VerifyOrganizationDomain() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		if h.authorizeOrganizationManager(ctx, c, orgID) == nil {
+			return
+		}
+		claim := h.loadOrganizationDomain(ctx, c, orgID)
+		if claim == nil {
+			return
+		}
+		if claim.Verified {
+			c.JSON(http.StatusOK, domainResponse(claim))
+			return
+		}
+
+		verified, err := h.Store.GetVerifiedOrganizationDomain(ctx, claim.Domain)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve domain"})
+			return
+		}
+		if verified != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is already verified by another organization"})
+			return
+		}
+
+		ok, err := h.DomainVerifier.Verify(ctx, claim.Domain, claim.VerificationToken)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to look up the verification record"})
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Verification record not found"})
+			return
+		}
+
+		// The unique index settles two organizations verifying the domain at the same time
+		err = h.Store.MarkOrganizationDomainVerified(ctx, orgID, claim.Domain)
+		if errors.Is(err, repository.ErrDomainAlreadyVerified) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Domain is already verified by another organization"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify domain"})
+			return
+		}
+		now := time.Now()
+		claim.Verified = true
+		claim.VerifiedAt = &now
+		c.JSON(http.StatusOK, domainResponse(claim))
+	}
+}
+
+// UpdateOrganizationDomain changes whether matching users join automatically and at which access level.
+func (h *Handler) UpdateOrganizationDomain() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		if h.authorizeOrganizationManager(ctx, c, orgID) == nil {
+			return
+		}
+		claim := h.loadOrganizationDomain(ctx, c, orgID)
+		if claim == nil {
+			return
+		}
+
+		var input DomainInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if input.AutoJoin != nil {
+			claim.AutoJoin = *input.AutoJoin
+		}
+		if input.DefaultAccessLevel != "" {
+			if !validAutoJoinAccessLevel(input.DefaultAccessLevel) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "default_access_level must be admin or member"})
+				return
+			}
+			claim.DefaultAccessLevel = input.DefaultAccessLevel
+		}
+
+		if err := h.Store.UpdateOrganizationDomainSettings(ctx, orgID, claim.Domain, claim.AutoJoin, claim.DefaultAccessLevel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update domain"})
+			return
+		}
+		c.JSON(http.StatusOK, domainResponse(claim))
+	}
+}
+
+// DeleteOrganizationDomain releases the organization's claim on a domain.
+func (h *Handler) DeleteOrganizationDomain() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		if h.authorizeOrganizationManager(ctx, c, orgID) == nil {
+			return
+		}
+		claim := h.loadOrganizationDomain(ctx, c, orgID)
+		if claim == nil {
+			return
+		}
+
+		if err := h.Store.DeleteOrganizationDomain(ctx, orgID, claim.Domain); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete domain"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Domain deleted successfully"})
+	}
+}
+
+// joinOrganizationByVerifiedEmail adds the user as a member of the organization that verified their email
+// domain, if it has auto-join turned on. Callers must have proof that the user controls the email, such as
+// the verified email claim of an identity provider; a password signup is not. Failures are logged so they
+// never block signing in.
+func (h *Handler) joinOrganizationByVerifiedEmail(ctx context.Context, user *model.User) {
+	domain := util.EmailDomain(user.Email)
+	if domain == "" {
+		return
+	}
+
+	claim, err := h.Store.GetVerifiedOrganizationDomain(ctx, domain)
+	if err != nil {
+		log.Printf("auto-join: looking up domain %s: %v", domain, err)
+		return
+	}
+	if claim == nil || !claim.AutoJoin {
+		return
+	}
+
+	// Claims saved before the access level was configurable have none
+	accessLevel := claim.DefaultAccessLevel
+	if !validAutoJoinAccessLevel(accessLevel) {
+		accessLevel = model.AccessLevelMember
+	}
+
+	// Existing members are left as they are
+	_, err = h.Store.AddOrganizationMember(ctx, claim.OrganizationId, model.OrganizationMember{
+		Name:        user.Name,
+		UserEmail:   user.Email,
+		AccessLevel: accessLevel,
+		Type:        model.MemberTypeUser,
+	})
+	if err != nil {
+		log.Printf("auto-join: adding %s to organization %s: %v", user.Email, claim.OrganizationId, err)
+	}
+}
+
+func (h *Handler) loadOrganizationDomain(ctx context.Context, c *gin.Context, orgID string) *model.OrganizationDomain {
+	domain, err := util.NormalizeDomain(c.Param("domain"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return nil
+	}
+	claim, err := h.Store.GetOrganizationDomain(ctx, orgID, domain)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve domain"})
+		return nil
+	}
+	if claim == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Domain not found"})
+		return nil
+	}
+	return claim
+}
+
+func domainResponse(claim *model.OrganizationDomain) gin.H {
+	response := gin.H{
+		"domain":               claim.Domain,
+		"verified":             claim.Verified,
+		"auto_join":            claim.AutoJoin,
+		"default_access_level": claim.DefaultAccessLevel,
+		"created_at":           claim.CreatedAt,
+	}
+	if claim.Verified {
+		response["verified_at"] = claim.VerifiedAt
+	} else {
+		response["verification"] = gin.H{
+			"type":  "TXT",
+			"name":  util.DomainChallengeRecord(claim.Domain),
+			"value": util.DomainChallengeValue(claim.VerificationToken),
+		}
+	}
+	return response
+}
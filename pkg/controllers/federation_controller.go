@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+	repository_token "organization_management/pkg/database/redis/repository"
+	"organization_management/pkg/metrics"
+	util "organization_management/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const federationStateLifespan = 10 * time.Minute
+
+// FederatedLogin redirects the user to an upstream identity provider.
+func (h *Handler) FederatedLogin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		providerName := c.Param("provider")
+		provider, err := h.Federation.Get(ctx, providerName)
+		if err != nil {
+			if errors.Is(err, util.ErrUnknownProvider) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider is unavailable"})
+			return
+		}
+
+		state, nonce, codeVerifier, err := util.GenerateFederationState()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+			return
+		}
+
+		stateRepo := repository_token.NewFederationStateRepository(h.Redis)
+		err = stateRepo.SaveState(ctx, state, repository_token.FederationState{
+			Provider:     providerName,
+			Nonce:        nonce,
+			CodeVerifier: codeVerifier,
+		}, federationStateLifespan)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start sign-in"})
+			return
+		}
+
+		c.Redirect(http.StatusFound, provider.AuthCodeURL(state, nonce, codeVerifier))
+	}
+}
+
+// FederatedCallback completes the sign-in with an upstream identity provider. The user is found by the linked
+// identity, then by verified email, and provisioned if neither exists. It responds like LoginUser.
+func (h *Handler) FederatedCallback() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		if errorCode := c.Query("error"); errorCode != "" {
+			metrics.RecordLogin(metrics.LoginMethodFederated, false)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Identity provider returned an error: " + errorCode})
+			return
+		}
+
+		stateRepo := repository_token.NewFederationStateRepository(h.Redis)
+		state, err := stateRepo.ConsumeState(ctx, c.Query("state"))
+		if err != nil || state.Provider != c.Param("provider") {
+			metrics.RecordLogin(metrics.LoginMethodFederated, false)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired sign-in state"})
+			return
+		}
+
+		provider, err := h.Federation.Get(ctx, state.Provider)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider is unavailable"})
+			return
+		}
+
+		identity, err := provider.Exchange(ctx, c.Query("code"), state.CodeVerifier, state.Nonce)
+		if err != nil {
+			metrics.RecordLogin(metrics.LoginMethodFederated, false)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to verify identity provider response"})
+			return
+		}
+		if identity.Email == "" || !identity.EmailVerified {
+			metrics.RecordLogin(metrics.LoginMethodFederated, false)
+			c.JSON(http.StatusForbidden, gin.H{"error": "Identity provider did not return a verified email"})
+			return
+		}
+
+		user, err := h.findOrProvisionFederatedUser(ctx, identity)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in user"})
+			return
+		}
+		if !user.IsActive() {
+			metrics.RecordLogin(metrics.LoginMethodFederated, false)
+			c.JSON(http.StatusForbidden, gin.H{"error": inactiveAccountMessage(user)})
+			return
+		}
+
+		// The provider vouched for the email above, so the user may join by its domain
+		h.joinOrganizationByVerifiedEmail(ctx, user)
+
+		userID := user.Id.Hex()
+		token, refreshToken, err := h.Tokens.GenerateToken(userID, user.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating tokens"})
+			return
+		}
+
+		tokenRepo := repository_token.NewTokenRepository(h.Redis)
+		if err := tokenRepo.SaveRefreshToken(ctx, userID, refreshToken); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save refresh token"})
+			return
+		}
+
+		metrics.RecordLogin(metrics.LoginMethodFederated, true)
+		c.JSON(http.StatusOK, gin.H{
+			"access_token":  token,
+			"refresh_token": refreshToken,
+			"message":       "Authentication successful",
+		})
+	}
+}
+
+func (h *Handler) findOrProvisionFederatedUser(ctx context.Context, identity *util.FederatedIdentity) (*model.User, error) {
+	link := model.FederatedIdentity{Provider: identity.Provider, Subject: identity.Subject}
+
+	user, err := h.Store.GetUserByFederatedIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil || user != nil {
+		return user, err
+	}
+
+	// Link an existing account with the same verified email
+	user, err = h.Store.GetUserByEmail(ctx, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		if err := h.Store.AddFederatedIdentity(ctx, user.Email, link); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	// Provision a new account without a password; it can only sign in through the provider
+	name := identity.Name
+	if name == "" {
+		name = strings.Split(identity.Email, "@")[0]
+	}
+	newUser := model.User{
+		Name:       name,
+		Email:      identity.Email,
+		Identities: []model.FederatedIdentity{link},
+	}
+	if _, err := h.Store.InsertUser(ctx, newUser); err != nil {
+		return nil, err
+	}
+	return h.Store.GetUserByEmail(ctx, identity.Email)
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"sync"
+
+	repository "organization_management/pkg/database/mongodb/repository"
+	util "organization_management/pkg/utils"
+
+	goredis "github.com/go-redis/redis/v8"
+)
+
+// Features are the configured services the handlers sign, hash and check with. The server builds
+// them from its configuration; tests can build their own.
+type Features struct {
+	Tokens          *util.TokenVerifier
+	PasswordHashing *util.PasswordHashing
+	PasswordPolicy  util.PasswordPolicy
+	LoginPolicy     util.LoginPolicy
+	Admins          util.AdminSettings
+	OIDC            *util.OIDCSigner
+	Federation      *util.FederatedProviders
+	// DomainVerifier checks domain claims. The server uses the configured one; tests can stub DNS.
+	DomainVerifier util.DomainVerifier
+}
+
+// Handler builds the route handlers on top of the stores the application connected to.
+type Handler struct {
+	Store *repository.Store
+	Redis *goredis.Client
+	Features
+
+	// dummyHash is compared against when a sign-in names an unknown email; see dummyPasswordHash.
+	dummyHash     string
+	dummyHashOnce sync.Once
+}
+
+// NewHandler returns a Handler that reads and writes through store and redisClient with features.
+func NewHandler(store *repository.Store, redisClient *goredis.Client, features Features) *Handler {
+	return &Handler{Store: store, Redis: redisClient, Features: features}
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dependencyCheckTimeout bounds each readiness check so a hung dependency can't stall the probe.
+const dependencyCheckTimeout = 2 * time.Second
+
+// DependencyCheck pings one service the API needs in order to serve requests.
+type DependencyCheck struct {
+	Name string
+	Ping func(ctx context.Context) error
+}
+
+// DependencyStatus is the result of one readiness check.
+type DependencyStatus struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Liveness answers as long as the process can serve HTTP. It checks no dependencies,
+// so an outage elsewhere doesn't get the process restarted.
+func Liveness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// Readiness pings every dependency in parallel and reports each one's status and latency.
+// It answers 503 when any dependency is down or when accepting reports false, as it does during shutdown.
+func Readiness(accepting func() bool, checks ...DependencyCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), dependencyCheckTimeout)
+		defer cancel()
+
+		statuses := make(map[string]DependencyStatus, len(checks))
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, check := range checks {
+			wg.Add(1)
+			go func(check DependencyCheck) {
+				defer wg.Done()
+				status := runDependencyCheck(ctx, check)
+				mu.Lock()
+				statuses[check.Name] = status
+				mu.Unlock()
+			}(check)
+		}
+		wg.Wait()
+
+		shuttingDown := !accepting()
+		ready := !shuttingDown
+		for _, status := range statuses {
+			if status.Status != "up" {
+				ready = false
+			}
+		}
+
+		code, status := http.StatusOK, "ready"
+		if !ready {
+			code, status = http.StatusServiceUnavailable, "not_ready"
+		}
+		c.JSON(code, gin.H{
+			"status":        status,
+			"shutting_down": shuttingDown,
+			"dependencies":  statuses,
+		})
+	}
+}
+
+func runDependencyCheck(ctx context.Context, check DependencyCheck) DependencyStatus {
+	started := time.Now()
+	err := check.Ping(ctx)
+	status := DependencyStatus{
+		Status:    "up",
+		LatencyMs: float64(time.Since(started).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = "down"
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,236 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+	"organization_management/pkg/metrics"
+	util "organization_management/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultInviteLinkLifespanHours = 7 * 24
+	maxInviteLinkLifespanHours     = 30 * 24
+)
+
+// InviteLinkInput represents the input data for creating an invite link
+type InviteLinkInput struct {
+	AccessLevel    string `json:"access_level"`
+	ExpiresInHours int    `json:"expires_in_hours"`
+	MaxUses        int    `json:"max_uses"`
+}
+
+// RedeemInviteLinkInput represents the input data for redeeming an invite link
+type RedeemInviteLinkInput struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// CreateInviteLink creates an invite link for the organization. The token is only returned once.
+func (h *Handler) CreateInviteLink() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		if h.authorizeOrganizationManager(ctx, c, orgID) == nil {
+			return
+		}
+
+		var input InviteLinkInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if input.AccessLevel == "" {
+			input.AccessLevel = model.AccessLevelMember
+		}
+		if input.AccessLevel != model.AccessLevelAdmin && input.AccessLevel != model.AccessLevelMember {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "access_level must be admin or member"})
+			return
+		}
+		if input.ExpiresInHours == 0 {
+			input.ExpiresInHours = defaultInviteLinkLifespanHours
+		}
+		if input.ExpiresInHours < 1 || input.ExpiresInHours > maxInviteLinkLifespanHours {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_hours must be between 1 and " + strconv.Itoa(maxInviteLinkLifespanHours)})
+			return
+		}
+		if input.MaxUses < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "max_uses must be zero (unlimited) or more"})
+			return
+		}
+
+		creatorEmail, err := util.ExtractUserEmail(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve current user"})
+			return
+		}
+
+		token, hashedToken, err := util.GenerateInviteToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invite link"})
+			return
+		}
+
+		now := time.Now()
+		link := model.InviteLink{
+			LinkId:         uuid.New().String(),
+			OrganizationId: orgID,
+			HashedToken:    hashedToken,
+			AccessLevel:    input.AccessLevel,
+			MaxUses:        input.MaxUses,
+			CreatedBy:      creatorEmail,
+			CreatedAt:      now,
+			ExpiresAt:      now.Add(time.Duration(input.ExpiresInHours) * time.Hour),
+		}
+		if err := h.Store.InsertInviteLink(ctx, link); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save invite link"})
+			return
+		}
+		metrics.RecordInviteSent(metrics.InviteKindLink)
+
+		c.JSON(http.StatusCreated, gin.H{
+			"token":       token,
+			"invite_link": link,
+			"message":     "Store this token now, it won't be shown again",
+		})
+	}
+}
+
+// ListInviteLinks lists the organization's invite links that can still be redeemed.
+func (h *Handler) ListInviteLinks() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		if h.authorizeOrganizationManager(ctx, c, orgID) == nil {
+			return
+		}
+
+		links, err := h.Store.GetActiveInviteLinksByOrganizationID(ctx, orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invite links"})
+			return
+		}
+		c.JSON(http.StatusOK, links)
+	}
+}
+
+// RevokeInviteLink stops an invite link from being redeemed.
+func (h *Handler) RevokeInviteLink() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		if h.authorizeOrganizationManager(ctx, c, orgID) == nil {
+			return
+		}
+
+		if err := h.Store.RevokeInviteLink(ctx, orgID, c.Param("link_id")); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Invite link revoked successfully"})
+	}
+}
+
+// RedeemInviteLink adds the current user to the organization of the invite link.
+func (h *Handler) RedeemInviteLink() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		claims, err := util.ExtractClaims(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve current user"})
+			return
+		}
+		if claims.TokenType == util.TokenTypeServiceAccount {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only users can redeem invite links"})
+			return
+		}
+
+		var input RedeemInviteLinkInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		user, err := h.Store.GetUserByEmail(ctx, claims.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the current user"})
+			return
+		}
+		if user == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only users can redeem invite links"})
+			return
+		}
+
+		// Check membership before using up one of the link's uses
+		hashedToken := util.HashInviteToken(input.Token)
+		link, err := h.Store.GetInviteLinkByHash(ctx, hashedToken)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invite link"})
+			return
+		}
+		if link == nil || !link.IsActive(time.Now()) {
+			c.JSON(http.StatusGone, gin.H{"error": "Invite link is invalid, expired or used up"})
+			return
+		}
+		org, err := h.Store.GetOrganizationByID(ctx, link.OrganizationId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusGone, gin.H{"error": "Invite link is invalid, expired or used up"})
+			return
+		}
+		if org.FindMember(user.Email) != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already a member of the organization"})
+			return
+		}
+
+		link, err = h.Store.ConsumeInviteLink(ctx, hashedToken)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeem invite link"})
+			return
+		}
+		if link == nil {
+			c.JSON(http.StatusGone, gin.H{"error": "Invite link is invalid, expired or used up"})
+			return
+		}
+
+		added, err := h.Store.AddOrganizationMember(ctx, org.OrganizationId, model.OrganizationMember{
+			Name:        user.Name,
+			UserEmail:   user.Email,
+			AccessLevel: link.AccessLevel,
+			Type:        model.MemberTypeUser,
+		})
+		if err != nil || !added {
+			// The use wasn't needed after all. Failing to give it back only costs the link one use.
+			_ = h.Store.ReleaseInviteLinkUse(ctx, link.LinkId)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
+			return
+		}
+		if !added {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already a member of the organization"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"organization_id": org.OrganizationId,
+			"access_level":    link.AccessLevel,
+			"message":         "Joined organization successfully",
+		})
+	}
+}
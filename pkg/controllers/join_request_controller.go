@@ -0,0 +1,317 @@
+package controller
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+	util "organization_management/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	maxJoinRequestMessageLength = 500
+	defaultDiscoverablePageSize = 50
+	maxDiscoverablePageSize     = 100
+)
+
+// JoinRequestInput represents the input data for requesting to join an organization
+type JoinRequestInput struct {
+	Message string `json:"message"`
+}
+
+// JoinRequestDecisionInput represents the optional input data for approving a join request
+type JoinRequestDecisionInput struct {
+	AccessLevel string `json:"access_level"`
+}
+
+// ListDiscoverableOrganizations lists the organizations that opted into being discoverable,
+// optionally filtered by a name search in q.
+func (h *Handler) ListDiscoverableOrganizations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		filter := bson.M{"discoverable": true}
+		if query := c.Query("q"); query != "" {
+			filter["name"] = bson.M{"$regex": "(?i)" + regexp.QuoteMeta(query)}
+		}
+
+		offset, limit, ok := parsePagination(c, defaultDiscoverablePageSize, maxDiscoverablePageSize)
+		if !ok {
+			return
+		}
+
+		orgs, total, err := h.Store.FindOrganizations(ctx, filter, offset, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organizations"})
+			return
+		}
+
+		orgList := make([]gin.H, 0, len(orgs))
+		for _, org := range orgs {
+			orgList = append(orgList, gin.H{
+				"organization_id": org.OrganizationId,
+				"name":            org.Name,
+				"description":     org.Description,
+				"member_count":    len(org.OrganizationMembers),
+			})
+		}
+		c.JSON(http.StatusOK, gin.H{"organizations": orgList, "total": total})
+	}
+}
+
+// parsePagination reads the offset and limit query parameters. It writes the error response and returns
+// false when they are invalid.
+func parsePagination(c *gin.Context, defaultSize, maxSize int) (int64, int64, bool) {
+	offset, err := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative number"})
+		return 0, 0, false
+	}
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", strconv.Itoa(defaultSize)), 10, 64)
+	if err != nil || limit < 1 || limit > int64(maxSize) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxSize)})
+		return 0, 0, false
+	}
+	return offset, limit, true
+}
+
+// CreateJoinRequest asks to join a discoverable organization on behalf of the current user.
+func (h *Handler) CreateJoinRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		claims, err := util.ExtractClaims(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve current user"})
+			return
+		}
+		if claims.TokenType == util.TokenTypeServiceAccount {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only users can request to join organizations"})
+			return
+		}
+
+		var input JoinRequestInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(input.Message) > maxJoinRequestMessageLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "message must be at most " + strconv.Itoa(maxJoinRequestMessageLength) + " characters"})
+			return
+		}
+
+		// Organizations that aren't discoverable look like they don't exist
+		orgID := c.Param("organization_id")
+		org, err := h.Store.GetOrganizationByID(ctx, orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return
+		}
+		if org == nil || !org.Discoverable {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+
+		user, err := h.Store.GetUserByEmail(ctx, claims.Email)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the current user"})
+			return
+		}
+		if user == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only users can request to join organizations"})
+			return
+		}
+		if org.FindMember(user.Email) != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is already a member of the organization"})
+			return
+		}
+
+		userID := user.Id.Hex()
+		pending, err := h.Store.GetPendingJoinRequest(ctx, orgID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve join requests"})
+			return
+		}
+		if pending != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "A join request is already pending", "request_id": pending.RequestId})
+			return
+		}
+
+		request := model.JoinRequest{
+			RequestId:      uuid.New().String(),
+			OrganizationId: orgID,
+			UserId:         userID,
+			UserEmail:      user.Email,
+			UserName:       user.Name,
+			Message:        input.Message,
+			Status:         model.JoinRequestPending,
+			CreatedAt:      time.Now(),
+		}
+		if err := h.Store.InsertJoinRequest(ctx, request); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save join request"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, request)
+	}
+}
+
+// ListJoinRequests lists the organization's join requests, optionally filtered by status.
+func (h *Handler) ListJoinRequests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		if h.authorizeOrganizationManager(ctx, c, orgID) == nil {
+			return
+		}
+
+		status := c.Query("status")
+		switch status {
+		case "", model.JoinRequestPending, model.JoinRequestApproved, model.JoinRequestRejected:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, approved or rejected"})
+			return
+		}
+
+		requests, err := h.Store.GetJoinRequestsByOrganizationID(ctx, orgID, status)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve join requests"})
+			return
+		}
+		c.JSON(http.StatusOK, requests)
+	}
+}
+
+// ApproveJoinRequest approves a pending join request and adds the user to the organization,
+// as a member unless another access_level is given.
+func (h *Handler) ApproveJoinRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		org := h.authorizeOrganizationManager(ctx, c, orgID)
+		if org == nil {
+			return
+		}
+
+		input := JoinRequestDecisionInput{AccessLevel: model.AccessLevelMember}
+		if c.Request.ContentLength > 0 {
+			if err := c.BindJSON(&input); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if input.AccessLevel != model.AccessLevelAdmin && input.AccessLevel != model.AccessLevelMember {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "access_level must be admin or member"})
+			return
+		}
+
+		request := h.loadPendingJoinRequest(ctx, c, orgID)
+		if request == nil {
+			return
+		}
+		user, err := h.Store.GetUserByID(ctx, request.UserId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+			return
+		}
+		if user == nil || !user.IsActive() {
+			c.JSON(http.StatusConflict, gin.H{"error": "The requesting user no longer exists or is not active"})
+			return
+		}
+
+		// The member is added before the request is marked approved, so a failure can't leave an approved
+		// request without a membership. Someone who joined in the meantime keeps their membership.
+		member := model.OrganizationMember{
+			Name:        user.Name,
+			UserEmail:   user.Email,
+			AccessLevel: input.AccessLevel,
+			Type:        model.MemberTypeUser,
+		}
+		added, err := h.Store.AddOrganizationMember(ctx, org.OrganizationId, member)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
+			return
+		}
+
+		if !h.decideJoinRequest(ctx, c, request, model.JoinRequestApproved) {
+			// The request was decided concurrently or couldn't be saved, take back the membership
+			if added {
+				if err := h.Store.RemoveAddedOrganizationMember(ctx, org.OrganizationId, member); err != nil {
+					log.Printf("join request %s: removing member %s: %v", request.RequestId, member.UserEmail, err)
+				}
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Join request approved"})
+	}
+}
+
+// RejectJoinRequest rejects a pending join request.
+func (h *Handler) RejectJoinRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		if h.authorizeOrganizationManager(ctx, c, orgID) == nil {
+			return
+		}
+
+		request := h.loadPendingJoinRequest(ctx, c, orgID)
+		if request == nil {
+			return
+		}
+		if !h.decideJoinRequest(ctx, c, request, model.JoinRequestRejected) {
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Join request rejected"})
+	}
+}
+
+func (h *Handler) loadPendingJoinRequest(ctx context.Context, c *gin.Context, orgID string) *model.JoinRequest {
+	request, err := h.Store.GetJoinRequest(ctx, orgID, c.Param("request_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve join request"})
+		return nil
+	}
+	if request == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Join request not found"})
+		return nil
+	}
+	if request.Status != model.JoinRequestPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Join request was already " + request.Status})
+		return nil
+	}
+	return request
+}
+
+// decideJoinRequest records the decision of the current user. It writes the error response and returns
+// false if the request was decided concurrently or the update failed.
+func (h *Handler) decideJoinRequest(ctx context.Context, c *gin.Context, request *model.JoinRequest, status string) bool {
+	deciderEmail, err := util.ExtractUserEmail(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve current user"})
+		return false
+	}
+	if err := h.Store.DecideJoinRequest(ctx, request.OrganizationId, request.RequestId, status, deciderEmail); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
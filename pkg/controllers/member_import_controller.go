@@ -0,0 +1,254 @@
+package controller
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+	util "organization_management/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// maxMemberImportBytes bounds the size of an uploaded import file.
+const maxMemberImportBytes = 5 << 20
+
+// Outcomes of a row in a member import.
+const (
+	memberImportAdded     = "added"
+	memberImportUpdated   = "updated"
+	memberImportUnchanged = "unchanged"
+)
+
+// memberImportResult is the outcome of one imported row.
+type memberImportResult struct {
+	Line        int    `json:"line"`
+	Email       string `json:"email"`
+	AccessLevel string `json:"access_level"`
+	Status      string `json:"status"`
+}
+
+// ImportOrganizationMembers adds or updates organization members from a CSV or JSON Lines file. Rows that
+// fail validation are reported and skipped while the others are applied, and running the same file again
+// leaves members unchanged. With dry_run=true nothing is saved.
+func (h *Handler) ImportOrganizationMembers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 30*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		org := h.authorizeOrganizationManager(ctx, c, orgID)
+		if org == nil {
+			return
+		}
+
+		dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return
+		}
+		format, err := util.MemberImportFormat(c.Query("format"), c.ContentType())
+		if err != nil {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+			return
+		}
+
+		body := http.MaxBytesReader(c.Writer, c.Request.Body, maxMemberImportBytes)
+		rows, rowErrors, err := util.ParseMemberImport(body, format)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		users, err := h.findImportUsers(ctx, rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve users"})
+			return
+		}
+
+		results := []memberImportResult{}
+		seen := map[string]bool{}
+		counts := map[string]int{}
+		for _, row := range rows {
+			result, message := applyMemberImportRow(org, row, users, seen)
+			if message != "" {
+				rowErrors = append(rowErrors, util.MemberImportError{Line: row.Line, Email: row.Email, Error: message})
+				continue
+			}
+			counts[result.Status]++
+			results = append(results, result)
+		}
+
+		if !dryRun {
+			var conflicts []util.MemberImportError
+			results, conflicts, err = h.saveMemberImport(ctx, orgID, results, users, counts)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
+				return
+			}
+			rowErrors = append(rowErrors, conflicts...)
+		}
+
+		if rowErrors == nil {
+			rowErrors = []util.MemberImportError{}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"dry_run":   dryRun,
+			"added":     counts[memberImportAdded],
+			"updated":   counts[memberImportUpdated],
+			"unchanged": counts[memberImportUnchanged],
+			"failed":    len(rowErrors),
+			"results":   results,
+			"errors":    rowErrors,
+		})
+	}
+}
+
+// ExportOrganizationMembers streams the organization's members as CSV.
+func (h *Handler) ExportOrganizationMembers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		org := h.authorizeOrganizationManager(ctx, c, orgID)
+		if org == nil {
+			return
+		}
+
+		if err := h.annotateMemberStatuses(ctx, org); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve member statuses"})
+			return
+		}
+
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="organization-`+orgID+`-members.csv"`)
+		c.Status(http.StatusOK)
+
+		writer := csv.NewWriter(c.Writer)
+		_ = writer.Write([]string{"name", "email", "access_level", "type", "status"})
+		for _, member := range org.OrganizationMembers {
+			memberType := member.Type
+			if memberType == "" {
+				memberType = model.MemberTypeUser
+			}
+			_ = writer.Write([]string{
+				util.CSVSafe(member.Name),
+				util.CSVSafe(member.UserEmail),
+				util.CSVSafe(member.AccessLevel),
+				memberType,
+				member.Status,
+			})
+		}
+		writer.Flush()
+	}
+}
+
+// applyMemberImportRow validates a row and applies it to the organization in memory.
+// It returns the outcome, or a description of the problem when the row is skipped.
+func applyMemberImportRow(org *model.Organization, row util.MemberImportRow, users map[string]*model.User, seen map[string]bool) (memberImportResult, string) {
+	if !model.ValidateEmail(row.Email) {
+		return memberImportResult{}, "Invalid email address"
+	}
+	key := strings.ToLower(row.Email)
+	if seen[key] {
+		return memberImportResult{}, "Email appears more than once in the file"
+	}
+	seen[key] = true
+
+	accessLevel := row.AccessLevel
+	if accessLevel == "" {
+		accessLevel = model.AccessLevelMember
+	}
+	if accessLevel != model.AccessLevelAdmin && accessLevel != model.AccessLevelMember {
+		return memberImportResult{}, "access_level must be admin or member"
+	}
+
+	user := users[key]
+	if user == nil {
+		return memberImportResult{}, "User not found"
+	}
+	result := memberImportResult{Line: row.Line, Email: user.Email, AccessLevel: accessLevel}
+
+	member := org.FindMember(user.Email)
+	switch {
+	case member == nil:
+		org.OrganizationMembers = append(org.OrganizationMembers, model.OrganizationMember{
+			Name:        user.Name,
+			UserEmail:   user.Email,
+			AccessLevel: accessLevel,
+			Type:        model.MemberTypeUser,
+		})
+		result.Status = memberImportAdded
+	case member.Type == model.MemberTypeServiceAccount:
+		return memberImportResult{}, "Service accounts can't be imported"
+	case member.AccessLevel == model.AccessLevelFounder:
+		return memberImportResult{}, "A Founder's access level can't be changed by an import"
+	case member.AccessLevel == accessLevel:
+		result.Status = memberImportUnchanged
+	default:
+		member.AccessLevel = accessLevel
+		result.Status = memberImportUpdated
+	}
+	return result, ""
+}
+
+// saveMemberImport applies the planned rows one member at a time, each checked and written in a single
+// update, so members added or changed concurrently are never overwritten. Rows whose member changed in the
+// meantime are returned as errors and no longer counted.
+func (h *Handler) saveMemberImport(ctx context.Context, orgID string, results []memberImportResult, users map[string]*model.User, counts map[string]int) ([]memberImportResult, []util.MemberImportError, error) {
+	saved := make([]memberImportResult, 0, len(results))
+	var conflicts []util.MemberImportError
+	for _, result := range results {
+		applied := true
+		var err error
+		switch result.Status {
+		case memberImportAdded:
+			user := users[strings.ToLower(result.Email)]
+			applied, err = h.Store.AddOrganizationMember(ctx, orgID, model.OrganizationMember{
+				Name:        user.Name,
+				UserEmail:   user.Email,
+				AccessLevel: result.AccessLevel,
+				Type:        model.MemberTypeUser,
+			})
+		case memberImportUpdated:
+			applied, err = h.Store.SetOrganizationMemberAccessLevel(ctx, orgID, result.Email, result.AccessLevel)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if !applied {
+			counts[result.Status]--
+			conflicts = append(conflicts, util.MemberImportError{Line: result.Line, Email: result.Email, Error: "The membership changed during the import, try again"})
+			continue
+		}
+		saved = append(saved, result)
+	}
+	return saved, conflicts, nil
+}
+
+// findImportUsers loads the users referenced by the rows, keyed by lowercased email.
+func (h *Handler) findImportUsers(ctx context.Context, rows []util.MemberImportRow) (map[string]*model.User, error) {
+	users := map[string]*model.User{}
+	if len(rows) == 0 {
+		return users, nil
+	}
+
+	emails := make([]string, 0, len(rows)*2)
+	for _, row := range rows {
+		emails = append(emails, row.Email, strings.ToLower(row.Email))
+	}
+	found, _, err := h.Store.FindUsers(ctx, bson.M{"email": bson.M{"$in": emails}}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range found {
+		users[strings.ToLower(found[i].Email)] = &found[i]
+	}
+	return users, nil
+}
@@ -0,0 +1,556 @@
+package controller
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+	repository_token "organization_management/pkg/database/redis/repository"
+	"organization_management/pkg/metrics"
+	util "organization_management/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	authorizationCodeLifespan = time.Minute
+	idTokenLifespan           = time.Hour
+
+	// authorizeCSRFCookie holds the anti-CSRF value of the sign-in form, which is posted back with it.
+	// A cross-site page can neither read the cookie nor make the browser send it, so it can't sign a
+	// victim in to the attacker's account.
+	authorizeCSRFCookie   = "oauth2_authorize_csrf"
+	authorizeFormLifespan = 10 * time.Minute
+)
+
+var oidcScopes = []string{"openid", "email", "profile", "organizations"}
+
+var authorizeFormTemplate = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Sign in to {{.ClientName}}</title></head>
+<body>
+<h1>Sign in to {{.ClientName}}</h1>
+{{if .Error}}<p style="color: red">{{.Error}}</p>{{end}}
+<form method="POST" action="/oauth2/authorize">
+{{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+<label>Email <input type="email" name="email" required></label>
+<label>Password <input type="password" name="password" required></label>
+<button type="submit">Sign in</button>
+</form>
+</body>
+</html>
+`))
+
+// authorizationRequest holds the parameters of an OIDC authorization request.
+type authorizationRequest struct {
+	ClientId            string
+	RedirectURI         string
+	ResponseType        string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+func (r *authorizationRequest) params() map[string]string {
+	return map[string]string{
+		"client_id":             r.ClientId,
+		"redirect_uri":          r.RedirectURI,
+		"response_type":         r.ResponseType,
+		"scope":                 r.Scope,
+		"state":                 r.State,
+		"nonce":                 r.Nonce,
+		"code_challenge":        r.CodeChallenge,
+		"code_challenge_method": r.CodeChallengeMethod,
+	}
+}
+
+func (r *authorizationRequest) hasScope(scope string) bool {
+	for _, requested := range strings.Fields(r.Scope) {
+		if requested == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// OIDCDiscovery serves the OpenID Provider configuration document.
+func (h *Handler) OIDCDiscovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		issuer := h.OIDC.Issuer()
+		c.JSON(http.StatusOK, gin.H{
+			"issuer":                                issuer,
+			"authorization_endpoint":                issuer + "/oauth2/authorize",
+			"token_endpoint":                        issuer + "/oauth2/token",
+			"userinfo_endpoint":                     issuer + "/oauth2/userinfo",
+			"jwks_uri":                              issuer + "/oauth2/jwks",
+			"response_types_supported":              []string{"code"},
+			"grant_types_supported":                 []string{"authorization_code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{jwt.SigningMethodRS256.Alg()},
+			"scopes_supported":                      oidcScopes,
+			"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+			"code_challenge_methods_supported":      []string{"S256"},
+			"claims_supported":                      []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "email", "name", "organizations"},
+		})
+	}
+}
+
+// OIDCKeys serves the public keys ID tokens are signed with.
+func (h *Handler) OIDCKeys() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, h.OIDC.JWKS())
+	}
+}
+
+// OIDCAuthorize starts the authorization code flow. Requests already authenticated with an access token get
+// a code right away, everyone else is shown a sign-in form. Impersonation tokens are refused, so an
+// administrator can't trade one for a token that isn't audited.
+func (h *Handler) OIDCAuthorize() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		req, client := h.parseAuthorizationRequest(ctx, c)
+		if req == nil {
+			return
+		}
+
+		if claims, ok := util.AuthenticatedClaims(c); ok && claims.TokenType == util.TokenTypeAccess {
+			if claims.IsImpersonated() {
+				c.String(http.StatusForbidden, "Client applications can't be authorized while impersonating a user")
+				return
+			}
+			h.issueAuthorizationCode(ctx, c, req, claims.UserID(), claims.Email)
+			return
+		}
+
+		h.renderAuthorizeForm(c, http.StatusOK, req, client, "")
+	}
+}
+
+// OIDCAuthorizeSubmit checks the credentials posted from the sign-in form and issues an authorization code.
+func (h *Handler) OIDCAuthorizeSubmit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		req, client := h.parseAuthorizationRequest(ctx, c)
+		if req == nil {
+			return
+		}
+
+		csrfCookie, err := c.Cookie(authorizeCSRFCookie)
+		if err != nil || !util.CSRFTokenMatches(c.PostForm("csrf_token"), csrfCookie) {
+			h.renderAuthorizeForm(c, http.StatusForbidden, req, client, "The sign-in form expired, please try again")
+			return
+		}
+
+		email := c.PostForm("email")
+		password := c.PostForm("password")
+		if email == "" || password == "" {
+			h.renderAuthorizeForm(c, http.StatusBadRequest, req, client, "Email and password are required")
+			return
+		}
+
+		// Sign-ins through the provider are throttled like regular ones
+		attemptRepo := repository_token.NewLoginAttemptRepository(h.Redis)
+		accountKey := "account:" + strings.ToLower(email)
+		ipKey := "ip:" + c.ClientIP()
+		retryAfter, err := loginBlockedFor(ctx, attemptRepo, accountKey, ipKey)
+		if err != nil {
+			h.renderAuthorizeForm(c, http.StatusInternalServerError, req, client, "Something went wrong, please try again")
+			return
+		}
+		if retryAfter > 0 {
+			metrics.RecordLogin(metrics.LoginMethodOIDCForm, false)
+			h.renderAuthorizeForm(c, http.StatusTooManyRequests, req, client, "Too many failed sign-in attempts, please try again later")
+			return
+		}
+
+		user, err := h.Store.GetUserByEmail(ctx, email)
+		if err != nil {
+			h.renderAuthorizeForm(c, http.StatusInternalServerError, req, client, "Something went wrong, please try again")
+			return
+		}
+		hashedPassword := h.dummyPasswordHash()
+		if user != nil {
+			hashedPassword = user.Password
+		}
+		if err := h.verifyPassword(ctx, password, hashedPassword); err != nil || user == nil {
+			if err := registerLoginFailure(ctx, attemptRepo, h.LoginPolicy, accountKey, ipKey); err != nil {
+				h.renderAuthorizeForm(c, http.StatusInternalServerError, req, client, "Something went wrong, please try again")
+				return
+			}
+			metrics.RecordLogin(metrics.LoginMethodOIDCForm, false)
+			h.renderAuthorizeForm(c, http.StatusUnauthorized, req, client, invalidCredentialsMessage)
+			return
+		}
+		if err := attemptRepo.Reset(ctx, accountKey); err != nil {
+			h.renderAuthorizeForm(c, http.StatusInternalServerError, req, client, "Something went wrong, please try again")
+			return
+		}
+		if !user.IsActive() {
+			metrics.RecordLogin(metrics.LoginMethodOIDCForm, false)
+			h.renderAuthorizeForm(c, http.StatusForbidden, req, client, inactiveAccountMessage(user))
+			return
+		}
+		if user.PasswordReset != nil {
+			metrics.RecordLogin(metrics.LoginMethodOIDCForm, false)
+			h.renderAuthorizeForm(c, http.StatusForbidden, req, client, "Password reset required, use the reset token from your administrator")
+			return
+		}
+
+		h.upgradePasswordHash(ctx, user, password)
+		metrics.RecordLogin(metrics.LoginMethodOIDCForm, true)
+
+		h.issueAuthorizationCode(ctx, c, req, user.Id.Hex(), user.Email)
+	}
+}
+
+// OIDCToken redeems an authorization code for an ID token and an access token.
+func (h *Handler) OIDCToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+		c.Header("Cache-Control", "no-store")
+
+		clientID, clientSecret, hasBasicAuth := c.Request.BasicAuth()
+		if !hasBasicAuth {
+			clientID = c.PostForm("client_id")
+			clientSecret = c.PostForm("client_secret")
+		}
+
+		client, err := h.Store.GetOIDCClientByClientID(ctx, clientID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+			return
+		}
+		if client == nil || (!client.Public && !util.ClientSecretMatches(clientSecret, client.HashedSecret)) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+			return
+		}
+
+		if c.PostForm("grant_type") != "authorization_code" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+			return
+		}
+
+		codeRepo := repository_token.NewAuthorizationCodeRepository(h.Redis)
+		code, err := codeRepo.ConsumeCode(ctx, c.PostForm("code"))
+		if err != nil || code.ClientId != client.ClientId || code.RedirectURI != c.PostForm("redirect_uri") ||
+			!util.VerifyPKCE(c.PostForm("code_verifier"), code.CodeChallenge, code.CodeChallengeMethod) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant"})
+			return
+		}
+
+		// The code's subject identifies the user; the email may have changed since the user consented
+		user, err := h.Store.GetUserByID(ctx, code.UserId)
+		if err != nil || user == nil || !user.IsActive() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant"})
+			return
+		}
+
+		accessToken, accessLifespan, err := h.Tokens.GenerateClientAccessToken(code.UserId, user.Email, client.ClientId, strings.Fields(code.Scope))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+			return
+		}
+
+		req := authorizationRequest{Scope: code.Scope}
+		claims, err := h.userInfoClaims(ctx, &req, user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+			return
+		}
+		delete(claims, "sub")
+		claims["auth_time"] = code.AuthTime.Unix()
+		if code.Nonce != "" {
+			claims["nonce"] = code.Nonce
+		}
+
+		idToken, err := h.OIDC.SignIDToken(code.UserId, client.ClientId, idTokenLifespan, claims)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"access_token": accessToken,
+			"token_type":   "Bearer",
+			"expires_in":   int(accessLifespan.Seconds()),
+			"id_token":     idToken,
+			"scope":        code.Scope,
+		})
+	}
+}
+
+// OIDCUserInfo returns the claims about the user the access token was issued to, limited to the scopes
+// the user granted the client.
+func (h *Handler) OIDCUserInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		claims, ok := util.AuthenticatedClaims(c)
+		if !ok || claims.TokenType != util.TokenTypeClientAccess {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_token"})
+			return
+		}
+
+		user, err := h.Store.GetUserByID(ctx, claims.UserID())
+		if err != nil || user == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_token"})
+			return
+		}
+
+		req := authorizationRequest{Scope: strings.Join(claims.Scopes, " ")}
+		info, err := h.userInfoClaims(ctx, &req, user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+			return
+		}
+		c.JSON(http.StatusOK, info)
+	}
+}
+
+// parseAuthorizationRequest validates an authorization request. Problems with the client or redirect URI are
+// shown to the user; anything else is sent back to the client's redirect URI. It returns nil once a response was written.
+func (h *Handler) parseAuthorizationRequest(ctx context.Context, c *gin.Context) (*authorizationRequest, *model.OIDCClient) {
+	req := &authorizationRequest{
+		ClientId:            c.Request.FormValue("client_id"),
+		RedirectURI:         c.Request.FormValue("redirect_uri"),
+		ResponseType:        c.Request.FormValue("response_type"),
+		Scope:               c.Request.FormValue("scope"),
+		State:               c.Request.FormValue("state"),
+		Nonce:               c.Request.FormValue("nonce"),
+		CodeChallenge:       c.Request.FormValue("code_challenge"),
+		CodeChallengeMethod: c.Request.FormValue("code_challenge_method"),
+	}
+
+	client, err := h.Store.GetOIDCClientByClientID(ctx, req.ClientId)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to retrieve client")
+		return nil, nil
+	}
+	if client == nil {
+		c.String(http.StatusBadRequest, "Unknown client")
+		return nil, nil
+	}
+	if !client.AllowsRedirectURI(req.RedirectURI) {
+		c.String(http.StatusBadRequest, "Redirect URI is not registered for this client")
+		return nil, nil
+	}
+
+	switch {
+	case req.ResponseType != "code":
+		redirectWithError(c, req, "unsupported_response_type", "Only the code response type is supported")
+		return nil, nil
+	case !req.hasScope("openid"):
+		redirectWithError(c, req, "invalid_scope", "The openid scope is required")
+		return nil, nil
+	case req.CodeChallenge == "" || req.CodeChallengeMethod != "S256":
+		redirectWithError(c, req, "invalid_request", "PKCE with the S256 method is required")
+		return nil, nil
+	}
+	return req, client
+}
+
+func (h *Handler) issueAuthorizationCode(ctx context.Context, c *gin.Context, req *authorizationRequest, userID, email string) {
+	code, err := util.GenerateAuthorizationCode()
+	if err != nil {
+		redirectWithError(c, req, "server_error", "Failed to generate authorization code")
+		return
+	}
+
+	codeRepo := repository_token.NewAuthorizationCodeRepository(h.Redis)
+	err = codeRepo.SaveCode(ctx, code, repository_token.AuthorizationCode{
+		ClientId:            req.ClientId,
+		RedirectURI:         req.RedirectURI,
+		UserId:              userID,
+		Email:               email,
+		Scope:               req.Scope,
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		AuthTime:            time.Now(),
+	}, authorizationCodeLifespan)
+	if err != nil {
+		redirectWithError(c, req, "server_error", "Failed to save authorization code")
+		return
+	}
+
+	redirectToClient(c, req, url.Values{"code": {code}})
+}
+
+func redirectWithError(c *gin.Context, req *authorizationRequest, code, description string) {
+	redirectToClient(c, req, url.Values{"error": {code}, "error_description": {description}})
+}
+
+func redirectToClient(c *gin.Context, req *authorizationRequest, values url.Values) {
+	target, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid redirect URI")
+		return
+	}
+	if req.State != "" {
+		values.Set("state", req.State)
+	}
+	query := target.Query()
+	for key, value := range values {
+		query[key] = value
+	}
+	target.RawQuery = query.Encode()
+	c.Redirect(http.StatusFound, target.String())
+}
+
+// renderAuthorizeForm shows the sign-in form with a fresh anti-CSRF value, which is also set as a cookie.
+func (h *Handler) renderAuthorizeForm(c *gin.Context, status int, req *authorizationRequest, client *model.OIDCClient, message string) {
+	csrfToken, err := util.GenerateCSRFToken()
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to render the sign-in form")
+		return
+	}
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(authorizeCSRFCookie, csrfToken, int(authorizeFormLifespan.Seconds()), "/oauth2/authorize", "",
+		strings.HasPrefix(h.OIDC.Issuer(), "https://"), true)
+
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	authorizeFormTemplate.Execute(c.Writer, gin.H{
+		"ClientName": client.Name,
+		"Params":     req.params(),
+		"CSRFToken":  csrfToken,
+		"Error":      message,
+	})
+}
+
+// userInfoClaims builds the claims about user that the requested scopes allow.
+func (h *Handler) userInfoClaims(ctx context.Context, req *authorizationRequest, user *model.User) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{"sub": user.Id.Hex()}
+	if req.hasScope("email") {
+		claims["email"] = user.Email
+	}
+	if req.hasScope("profile") {
+		claims["name"] = user.Name
+	}
+	if req.hasScope("organizations") {
+		orgs, err := h.Store.GetOrganizationsByMemberEmail(ctx, user.Email)
+		if err != nil {
+			return nil, err
+		}
+		memberships := []gin.H{}
+		for _, org := range orgs {
+			if member := org.FindMember(user.Email); member != nil {
+				memberships = append(memberships, gin.H{
+					"organization_id": org.OrganizationId,
+					"name":            org.Name,
+					"access_level":    member.AccessLevel,
+				})
+			}
+		}
+		claims["organizations"] = memberships
+	}
+	return claims, nil
+}
+
+// OIDCClientInput represents the input data for registering a client application
+type OIDCClientInput struct {
+	Name         string   `json:"name" binding:"required"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required"`
+	Public       bool     `json:"public"`
+}
+
+// RegisterOIDCClient registers a client application. The client secret is only returned once.
+func (h *Handler) RegisterOIDCClient() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		var input OIDCClientInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(input.RedirectURIs) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "At least one redirect URI is required"})
+			return
+		}
+		for _, uri := range input.RedirectURIs {
+			if parsed, err := url.Parse(uri); err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid redirect URI: " + uri})
+				return
+			}
+		}
+
+		clientID, clientSecret, hashedSecret, err := util.GenerateClientCredentials("oidc_")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate client credentials"})
+			return
+		}
+
+		client := model.OIDCClient{
+			ClientId:     clientID,
+			Name:         input.Name,
+			RedirectURIs: input.RedirectURIs,
+			Public:       input.Public,
+			CreatedAt:    time.Now(),
+		}
+		if !client.Public {
+			client.HashedSecret = hashedSecret
+		}
+		if err := h.Store.InsertOIDCClient(ctx, client); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save client"})
+			return
+		}
+
+		response := gin.H{
+			"client_id":     client.ClientId,
+			"name":          client.Name,
+			"redirect_uris": client.RedirectURIs,
+			"public":        client.Public,
+		}
+		if !client.Public {
+			response["client_secret"] = clientSecret
+			response["message"] = "Store this secret now, it won't be shown again"
+		}
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+// ListOIDCClients lists the registered client applications.
+func (h *Handler) ListOIDCClients() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		clients, err := h.Store.GetAllOIDCClients(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve clients"})
+			return
+		}
+		c.JSON(http.StatusOK, clients)
+	}
+}
+
+// DeleteOIDCClient removes a client application.
+func (h *Handler) DeleteOIDCClient() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		if err := h.Store.DeleteOIDCClient(ctx, c.Param("client_id")); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Client deleted successfully"})
+	}
+}
@@ -3,18 +3,20 @@ package controller
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	model "organization_management/pkg/database/mongodb/models"
-	repository "organization_management/pkg/database/mongodb/repository"
+	"organization_management/pkg/metrics"
 	util "organization_management/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
-func CreateOrganization() gin.HandlerFunc {
+func (h *Handler) CreateOrganization() gin.HandlerFunc {
     return func(c *gin.Context) {
-        ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        ctx, cancel := requestContext(c, 10*time.Second)
         defer cancel()
 
         var org model.Organization
@@ -39,45 +41,61 @@ func CreateOrganization() gin.HandlerFunc {
 		}
 
 		// Retrieve the current authorized user by email
-		user, err := repository.GetUserByEmail(ctx, CurrentUserEmail)
+		user, err := h.Store.GetUserByEmail(ctx, CurrentUserEmail)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the current user"})
 			return
 		}
+		if user == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only users can create organizations"})
+			return
+		}
 
 		// Add the user to the organization members
 		orgMember := model.OrganizationMember{
 			Name:        user.Name,
 			UserEmail:       user.Email,
-			AccessLevel: "Founder",
+			AccessLevel: model.AccessLevelFounder,
+			Type:        model.MemberTypeUser,
 		}
 		org.OrganizationMembers = append(org.OrganizationMembers, orgMember)
 
         // Insert the organization into the database
-        _, organizationId, err := repository.InsertOrganization(ctx, org)
+        _, organizationId, err := h.Store.InsertOrganization(ctx, org)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
             return
         }
 
+        metrics.RecordOrganizationCreated()
         c.JSON(http.StatusCreated, gin.H{"organization_id": organizationId})
     }
 }
 
-func ReadOrganization() gin.HandlerFunc {
+func (h *Handler) ReadOrganization() gin.HandlerFunc {
     return func(c *gin.Context) {
-        ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        ctx, cancel := requestContext(c, 10*time.Second)
         defer cancel()
 
         // Extract organization ID from the request path parameters
         orgID := c.Param("organization_id")
 
         // Retrieve the organization from the database by its ID
-        org, err := repository.GetOrganizationByID(ctx, orgID)
+        org, err := h.Store.GetOrganizationByID(ctx, orgID)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
             return
         }
+        if org == nil {
+            c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+            return
+        }
+
+        // Show which members are suspended or disabled
+        if err := h.annotateMemberStatuses(ctx, org); err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve member statuses"})
+            return
+        }
 
 		// Check if organization members list is nil, and replace with an empty list if so
         var members []model.OrganizationMember
@@ -93,22 +111,44 @@ func ReadOrganization() gin.HandlerFunc {
             "name":                   org.Name,
             "description":            org.Description,
             "organization_members":   members,
+            "discoverable":           org.Discoverable,
         })
     }
 }
 
-func ReadAllOrganizations() gin.HandlerFunc {
+func (h *Handler) ReadAllOrganizations() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := requestContext(c, 10*time.Second)
 		defer cancel()
 
 		// Retrieve all organizations from the database
-		orgs, err := repository.GetAllOrganizations(ctx)
+		orgs, err := h.Store.GetAllOrganizations(ctx)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organizations"})
 			return
 		}
 
+		// Credentials bound to an organization only see that organization
+		if claims, err := util.ExtractClaims(c); err == nil && claims.OrganizationId != "" {
+			var visible []model.Organization
+			for _, org := range orgs {
+				if org.OrganizationId == claims.OrganizationId {
+					visible = append(visible, org)
+				}
+			}
+			orgs = visible
+		}
+
+		// Show which members are suspended or disabled
+		orgRefs := make([]*model.Organization, len(orgs))
+		for i := range orgs {
+			orgRefs[i] = &orgs[i]
+		}
+		if err := h.annotateMemberStatuses(ctx, orgRefs...); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve member statuses"})
+			return
+		}
+
 		// Prepare the response JSON array
 		var orgList []gin.H
 		for _, org := range orgs {
@@ -126,6 +166,7 @@ func ReadAllOrganizations() gin.HandlerFunc {
 				"name":                 org.Name,
 				"description":          org.Description,
 				"organization_members": members,
+				"discoverable":         org.Discoverable,
 			})
 		}
 
@@ -134,23 +175,31 @@ func ReadAllOrganizations() gin.HandlerFunc {
 	}
 }
 
-func UpdateOrganization() gin.HandlerFunc {
+func (h *Handler) UpdateOrganization() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := requestContext(c, 10*time.Second)
 		defer cancel()
 
 		// Extract organization ID from the request path parameters
 		orgID := c.Param("organization_id")
 
 		// Retrieve the organization from the database by its ID
-		org, err := repository.GetOrganizationByID(ctx, orgID)
+		org, err := h.Store.GetOrganizationByID(ctx, orgID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
 			return
 		}
+		if org == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
 
 		// Bind the request body to a struct
-		var updateData model.Organization
+		var updateData struct {
+			Name         string `json:"name"`
+			Description  string `json:"description"`
+			Discoverable *bool  `json:"discoverable"`
+		}
 		if err := c.BindJSON(&updateData); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -163,9 +212,12 @@ func UpdateOrganization() gin.HandlerFunc {
 		if updateData.Description != "" {
 			org.Description = updateData.Description
 		}
+		if updateData.Discoverable != nil {
+			org.Discoverable = *updateData.Discoverable
+		}
 
 		// Update the organization in the database
-		err = repository.UpdateOrganization(ctx, org)
+		err = h.Store.UpdateOrganization(ctx, org)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
 			return
@@ -176,23 +228,33 @@ func UpdateOrganization() gin.HandlerFunc {
 			"organization_id": orgID,
 			"name":            org.Name,
 			"description":     org.Description,
+			"discoverable":    org.Discoverable,
 		})
 	}
 }
 
 // DeleteOrganization deletes an organization by its ID.
-func DeleteOrganization() gin.HandlerFunc {
+func (h *Handler) DeleteOrganization() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := requestContext(c, 10*time.Second)
 		defer cancel()
 
 		// Extract organization ID from the request path parameters
 		orgID := c.Param("organization_id")
 
-		// Delete the organization from the database
-		err := repository.DeleteOrganization(ctx, orgID)
+		org, err := h.Store.GetOrganizationByID(ctx, orgID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return
+		}
+
+		// Delete the organization from the database
+		if err := h.deleteOrganization(ctx, orgID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organization"})
 			return
 		}
 
@@ -202,16 +264,16 @@ func DeleteOrganization() gin.HandlerFunc {
 }
 
 // InviteUserToOrganization invites a user to join an organization.
-func InviteUserToOrganization() gin.HandlerFunc {
+func (h *Handler) InviteUserToOrganization() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := requestContext(c, 10*time.Second)
 		defer cancel()
 
 		// Extract organization ID from the request path parameters
 		orgID := c.Param("organization_id")
 
 		// Retrieve the organization from the database by its ID
-		org, err := repository.GetOrganizationByID(ctx, orgID)
+		org, err := h.Store.GetOrganizationByID(ctx, orgID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
 			return
@@ -232,16 +294,8 @@ func InviteUserToOrganization() gin.HandlerFunc {
 			return
 		}
 
-		// Check if the user email is already in the organization
-		for _, member := range org.OrganizationMembers {
-			if member.UserEmail == inviteData.UserEmail {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "User is already a member of the organization"})
-				return
-			}
-		}
-
 		// Retrieve the user by email
-		user, err := repository.GetUserByEmail(ctx, inviteData.UserEmail)
+		user, err := h.Store.GetUserByEmail(ctx, inviteData.UserEmail)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
 			return
@@ -256,25 +310,32 @@ func InviteUserToOrganization() gin.HandlerFunc {
 		// Add the user to the organization members
 		orgMember := model.OrganizationMember{
 			Name:        user.Name,
-			UserEmail:       inviteData.UserEmail,
-			AccessLevel: "member",
+			UserEmail:   user.Email,
+			AccessLevel: model.AccessLevelMember,
+			Type:        model.MemberTypeUser,
 		}
-		org.OrganizationMembers = append(org.OrganizationMembers, orgMember)
 
-		// Update the organization in the database
-		if err := repository.UpdateOrganization(ctx, org); err != nil {
+		// Add the member unless they already belong to the organization, in one update
+		added, err := h.Store.AddOrganizationMember(ctx, org.OrganizationId, orgMember)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
 			return
 		}
+		if !added {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User is already a member of the organization"})
+			return
+		}
+
+		metrics.RecordInviteSent(metrics.InviteKindDirect)
 
 		// Return success message
 		c.JSON(http.StatusOK, gin.H{"message": "User invited successfully"})
 	}
 }
 
-func GetUserOrganizations() gin.HandlerFunc {
+func (h *Handler) GetUserOrganizations() gin.HandlerFunc {
     return func(c *gin.Context) {
-        ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        ctx, cancel := requestContext(c, 10*time.Second)
         defer cancel()
 
         // Get the current user's email
@@ -285,7 +346,7 @@ func GetUserOrganizations() gin.HandlerFunc {
         }
 
         // Retrieve the user from the database by email
-        user, err := repository.GetUserByEmail(ctx, currentUserEmail)
+        user, err := h.Store.GetUserByEmail(ctx, currentUserEmail)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
             return
@@ -298,7 +359,7 @@ func GetUserOrganizations() gin.HandlerFunc {
         }
 
         // Retrieve organizations where the user is a member
-        orgs, err := repository.GetOrganizationsByMemberEmail(ctx, currentUserEmail)
+        orgs, err := h.Store.GetOrganizationsByMemberEmail(ctx, currentUserEmail)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organizations"})
             return
@@ -328,3 +389,88 @@ func GetUserOrganizations() gin.HandlerFunc {
         c.JSON(http.StatusOK, orgList)
     }
 }
+
+// authorizeOrganizationManager loads the organization and makes sure the current credential belongs to
+// one of its Founders or admins. It writes the error response and returns nil when the check fails.
+func (h *Handler) authorizeOrganizationManager(ctx context.Context, c *gin.Context, orgID string) *model.Organization {
+	org, err := h.Store.GetOrganizationByID(ctx, orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve organization"})
+		return nil
+	}
+	if org == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		return nil
+	}
+
+	currentUserEmail, err := util.ExtractUserEmail(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve current user"})
+		return nil
+	}
+
+	member := org.FindMember(currentUserEmail)
+	if member == nil || !model.CanManageOrganization(member.AccessLevel) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only organization Founders and admins can do this"})
+		return nil
+	}
+	return org
+}
+
+// deleteOrganization releases what belongs to the organization and then deletes it. Releasing first means
+// a failure leaves the organization in place to retry, rather than deleted with its service accounts still working.
+func (h *Handler) deleteOrganization(ctx context.Context, orgID string) error {
+	if err := h.releaseOrganizationResources(ctx, orgID); err != nil {
+		return err
+	}
+	return h.Store.DeleteOrganization(ctx, orgID)
+}
+
+// releaseOrganizationResources cleans up what belongs to an organization that is being deleted. Service accounts
+// are disabled rather than deleted so their tokens stop working while the records stay around.
+func (h *Handler) releaseOrganizationResources(ctx context.Context, orgID string) error {
+	if err := h.Store.DisableServiceAccountsByOrganizationID(ctx, orgID); err != nil {
+		return err
+	}
+	if err := h.Store.DeleteOrganizationDomainsByOrganizationID(ctx, orgID); err != nil {
+		return err
+	}
+	if err := h.Store.RevokeInviteLinksByOrganizationID(ctx, orgID); err != nil {
+		return err
+	}
+	return h.Store.DeleteJoinRequestsByOrganizationID(ctx, orgID)
+}
+
+// annotateMemberStatuses fills in the account state of the organizations' members for responses.
+// Service accounts and members who haven't signed up yet are left without one.
+func (h *Handler) annotateMemberStatuses(ctx context.Context, orgs ...*model.Organization) error {
+	emails := []string{}
+	for _, org := range orgs {
+		for _, member := range org.OrganizationMembers {
+			if member.Type != model.MemberTypeServiceAccount {
+				emails = append(emails, member.UserEmail, strings.ToLower(member.UserEmail))
+			}
+		}
+	}
+	if len(emails) == 0 {
+		return nil
+	}
+
+	users, _, err := h.Store.FindUsers(ctx, bson.M{"email": bson.M{"$in": emails}}, 0, 0)
+	if err != nil {
+		return err
+	}
+	statuses := map[string]string{}
+	for i := range users {
+		statuses[strings.ToLower(users[i].Email)] = users[i].AccountStatus()
+	}
+	for _, org := range orgs {
+		for i := range org.OrganizationMembers {
+			member := &org.OrganizationMembers[i]
+			if member.Type != model.MemberTypeServiceAccount {
+				member.Status = statuses[strings.ToLower(member.UserEmail)]
+			}
+		}
+	}
+	return nil
+}
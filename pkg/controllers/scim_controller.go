@@ -0,0 +1,926 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+	util "organization_management/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	scimDefaultCount = 100
+	scimMaxCount     = 200
+)
+
+// scimUserInput is the body of a SCIM user create or replace request.
+type scimUserInput struct {
+	ExternalId  string `json:"externalId"`
+	UserName    string `json:"userName"`
+	DisplayName string `json:"displayName"`
+	Name        struct {
+		Formatted  string `json:"formatted"`
+		GivenName  string `json:"givenName"`
+		FamilyName string `json:"familyName"`
+	} `json:"name"`
+	Emails []struct {
+		Value   string `json:"value"`
+		Primary bool   `json:"primary"`
+	} `json:"emails"`
+	Active   *bool  `json:"active"`
+	Password string `json:"password"`
+}
+
+func (in *scimUserInput) email() string {
+	if in.UserName != "" {
+		return strings.ToLower(in.UserName)
+	}
+	for _, email := range in.Emails {
+		if email.Primary {
+			return strings.ToLower(email.Value)
+		}
+	}
+	if len(in.Emails) > 0 {
+		return strings.ToLower(in.Emails[0].Value)
+	}
+	return ""
+}
+
+func (in *scimUserInput) displayName() string {
+	switch {
+	case in.DisplayName != "":
+		return in.DisplayName
+	case in.Name.Formatted != "":
+		return in.Name.Formatted
+	case in.Name.GivenName != "" || in.Name.FamilyName != "":
+		return strings.TrimSpace(in.Name.GivenName + " " + in.Name.FamilyName)
+	}
+	return strings.Split(in.email(), "@")[0]
+}
+
+// scimGroupInput is the body of a SCIM group create or replace request.
+type scimGroupInput struct {
+	DisplayName string            `json:"displayName"`
+	Members     []scimMemberInput `json:"members"`
+}
+
+type scimMemberInput struct {
+	Value string `json:"value"`
+}
+
+// SCIMListUsers lists users, optionally filtered by userName, emails.value, externalId or displayName.
+func (h *Handler) SCIMListUsers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		filter, ok := scimQueryFilter(c, map[string]string{
+			"username":     "email",
+			"emails.value": "email",
+			"emails":       "email",
+			"externalid":   "externalid",
+			"displayname":  "name",
+		})
+		if !ok {
+			return
+		}
+		startIndex, count := scimPagination(c)
+		if count == 0 {
+			total, err := h.Store.CountUsers(ctx, filter)
+			if err != nil {
+				scimError(c, http.StatusInternalServerError, "", "Failed to retrieve users")
+				return
+			}
+			scimJSON(c, http.StatusOK, scimListResponse([]gin.H{}, total, startIndex))
+			return
+		}
+
+		users, total, err := h.Store.FindUsers(ctx, filter, startIndex-1, count)
+		if err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to retrieve users")
+			return
+		}
+
+		resources := make([]gin.H, 0, len(users))
+		for i := range users {
+			resources = append(resources, scimUserResource(&users[i]))
+		}
+		scimJSON(c, http.StatusOK, scimListResponse(resources, total, startIndex))
+	}
+}
+
+// SCIMGetUser returns a single user.
+func (h *Handler) SCIMGetUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		user := h.scimLoadUser(ctx, c)
+		if user == nil {
+			return
+		}
+		scimJSON(c, http.StatusOK, scimUserResource(user))
+	}
+}
+
+// SCIMCreateUser provisions a user. Users created without a password can only sign in through federation.
+func (h *Handler) SCIMCreateUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		var input scimUserInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+			return
+		}
+		email := input.email()
+		if !model.ValidateEmail(email) {
+			scimError(c, http.StatusBadRequest, "invalidValue", "userName must be a valid email address")
+			return
+		}
+
+		existing, err := h.Store.GetUserByEmail(ctx, email)
+		if err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to check user existence")
+			return
+		}
+		if existing != nil {
+			scimError(c, http.StatusConflict, "uniqueness", "User with this userName already exists")
+			return
+		}
+
+		user := model.User{
+			Name:       input.displayName(),
+			Email:      email,
+			Password:   input.Password,
+			ExternalId: input.ExternalId,
+			Status:     model.UserStatusActive,
+		}
+		if input.Active != nil {
+			setSCIMActive(&user, *input.Active)
+		}
+		if user.Password != "" {
+			violations, err := h.PasswordPolicy.Check(user.Password, user.Email, user.Name)
+			if err != nil {
+				scimError(c, http.StatusInternalServerError, "", "Failed to check password")
+				return
+			}
+			if len(violations) > 0 {
+				messages := make([]string, len(violations))
+				for i, violation := range violations {
+					messages[i] = violation.Message
+				}
+				scimError(c, http.StatusBadRequest, "invalidValue", strings.Join(messages, "; "))
+				return
+			}
+			if err := h.hashUserPassword(ctx, &user); err != nil {
+				scimError(c, http.StatusInternalServerError, "", "Failed to hash password")
+				return
+			}
+		}
+		if _, err := h.Store.InsertUser(ctx, user); err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to create user")
+			return
+		}
+
+		created, err := h.Store.GetUserByEmail(ctx, email)
+		if err != nil || created == nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to retrieve created user")
+			return
+		}
+		scimJSON(c, http.StatusCreated, scimUserResource(created))
+	}
+}
+
+// SCIMReplaceUser replaces a user's attributes. Setting active to false deprovisions the user.
+func (h *Handler) SCIMReplaceUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		user := h.scimLoadUser(ctx, c)
+		if user == nil {
+			return
+		}
+
+		var input scimUserInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+			return
+		}
+		email := input.email()
+		if !model.ValidateEmail(email) {
+			scimError(c, http.StatusBadRequest, "invalidValue", "userName must be a valid email address")
+			return
+		}
+
+		updated := *user
+		updated.Name = input.displayName()
+		updated.Email = email
+		updated.ExternalId = input.ExternalId
+		if input.Active != nil {
+			setSCIMActive(&updated, *input.Active)
+		}
+
+		if !h.scimSaveUser(ctx, c, user, &updated) {
+			return
+		}
+		scimJSON(c, http.StatusOK, scimUserResource(&updated))
+	}
+}
+
+// SCIMPatchUser applies SCIM PATCH operations to a user. Setting active to false deprovisions the user.
+func (h *Handler) SCIMPatchUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		user := h.scimLoadUser(ctx, c)
+		if user == nil {
+			return
+		}
+
+		var patch util.SCIMPatchRequest
+		if err := c.ShouldBindJSON(&patch); err != nil {
+			scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+			return
+		}
+
+		updated := *user
+		for _, operation := range patch.Operations {
+			op := strings.ToLower(operation.Op)
+			if op != "add" && op != "replace" && op != "remove" {
+				scimError(c, http.StatusBadRequest, "invalidSyntax", "Unsupported operation: "+operation.Op)
+				return
+			}
+
+			// Without a path the value holds the attributes to set
+			values := map[string]interface{}{}
+			if operation.Path == "" {
+				object, ok := operation.Value.(map[string]interface{})
+				if !ok {
+					scimError(c, http.StatusBadRequest, "invalidValue", "Operation without a path needs an object value")
+					return
+				}
+				values = object
+			} else {
+				values[operation.Path] = operation.Value
+			}
+
+			for path, value := range values {
+				if op == "remove" {
+					value = nil
+				}
+				if detail := applySCIMUserAttribute(&updated, path, value); detail != "" {
+					scimError(c, http.StatusBadRequest, "invalidPath", detail)
+					return
+				}
+			}
+		}
+		if !model.ValidateEmail(updated.Email) {
+			scimError(c, http.StatusBadRequest, "invalidValue", "userName must be a valid email address")
+			return
+		}
+
+		if !h.scimSaveUser(ctx, c, user, &updated) {
+			return
+		}
+		scimJSON(c, http.StatusOK, scimUserResource(&updated))
+	}
+}
+
+// SCIMDeleteUser deprovisions and deletes a user.
+func (h *Handler) SCIMDeleteUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		user := h.scimLoadUser(ctx, c)
+		if user == nil {
+			return
+		}
+
+		if err := h.deprovisionUser(ctx, user); err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to deprovision user")
+			return
+		}
+		if err := h.Store.DeleteUser(ctx, user.Id); err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to delete user")
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// SCIMListGroups lists organizations as groups, optionally filtered by displayName.
+func (h *Handler) SCIMListGroups() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		filter, ok := scimQueryFilter(c, map[string]string{"displayname": "name"})
+		if !ok {
+			return
+		}
+		startIndex, count := scimPagination(c)
+		if count == 0 {
+			total, err := h.Store.CountOrganizations(ctx, filter)
+			if err != nil {
+				scimError(c, http.StatusInternalServerError, "", "Failed to retrieve groups")
+				return
+			}
+			scimJSON(c, http.StatusOK, scimListResponse([]gin.H{}, total, startIndex))
+			return
+		}
+
+		orgs, total, err := h.Store.FindOrganizations(ctx, filter, startIndex-1, count)
+		if err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to retrieve groups")
+			return
+		}
+
+		resources := make([]gin.H, 0, len(orgs))
+		for i := range orgs {
+			resource, err := h.scimGroupResource(ctx, &orgs[i])
+			if err != nil {
+				scimError(c, http.StatusInternalServerError, "", "Failed to retrieve group members")
+				return
+			}
+			resources = append(resources, resource)
+		}
+		scimJSON(c, http.StatusOK, scimListResponse(resources, total, startIndex))
+	}
+}
+
+// SCIMGetGroup returns a single organization as a group.
+func (h *Handler) SCIMGetGroup() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		org := h.scimLoadGroup(ctx, c)
+		if org == nil {
+			return
+		}
+		h.scimRespondGroup(ctx, c, http.StatusOK, org)
+	}
+}
+
+// SCIMCreateGroup creates an organization from a group. Members join with the member access level and the
+// administrator provisioning the group becomes its Founder, since SCIM has no notion of an owner.
+func (h *Handler) SCIMCreateGroup() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		var input scimGroupInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+			return
+		}
+		if input.DisplayName == "" {
+			scimError(c, http.StatusBadRequest, "invalidValue", "displayName is required")
+			return
+		}
+
+		claims, ok := util.AuthenticatedClaims(c)
+		if !ok {
+			scimError(c, http.StatusUnauthorized, "", "Authentication required")
+			return
+		}
+		owner, err := h.Store.GetUserByID(ctx, claims.UserID())
+		if err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to retrieve user")
+			return
+		}
+		if owner == nil {
+			scimError(c, http.StatusForbidden, "", "Groups can only be provisioned with a user's credentials")
+			return
+		}
+
+		org := model.Organization{
+			Name:        input.DisplayName,
+			Description: "Provisioned through SCIM",
+			OrganizationMembers: []model.OrganizationMember{{
+				Name:        owner.Name,
+				UserEmail:   owner.Email,
+				AccessLevel: model.AccessLevelFounder,
+				Type:        model.MemberTypeUser,
+			}},
+		}
+		if detail := h.setSCIMGroupMembers(ctx, &org, input.Members); detail != "" {
+			scimError(c, http.StatusBadRequest, "invalidValue", detail)
+			return
+		}
+
+		_, orgID, err := h.Store.InsertOrganization(ctx, org)
+		if err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to create group")
+			return
+		}
+		org.OrganizationId = orgID
+		h.scimRespondGroup(ctx, c, http.StatusCreated, &org)
+	}
+}
+
+// SCIMReplaceGroup replaces a group's name and user members. Founders and service accounts are left in place.
+func (h *Handler) SCIMReplaceGroup() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		org := h.scimLoadGroup(ctx, c)
+		if org == nil {
+			return
+		}
+
+		var input scimGroupInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+			return
+		}
+		if input.DisplayName == "" {
+			scimError(c, http.StatusBadRequest, "invalidValue", "displayName is required")
+			return
+		}
+
+		before := append([]model.OrganizationMember(nil), org.OrganizationMembers...)
+		org.Name = input.DisplayName
+		if detail := h.setSCIMGroupMembers(ctx, org, input.Members); detail != "" {
+			scimError(c, http.StatusBadRequest, "invalidValue", detail)
+			return
+		}
+		h.scimSaveGroup(ctx, c, org, before)
+	}
+}
+
+// SCIMPatchGroup applies SCIM PATCH operations to a group's name and members.
+func (h *Handler) SCIMPatchGroup() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		org := h.scimLoadGroup(ctx, c)
+		if org == nil {
+			return
+		}
+
+		var patch util.SCIMPatchRequest
+		if err := c.ShouldBindJSON(&patch); err != nil {
+			scimError(c, http.StatusBadRequest, "invalidSyntax", err.Error())
+			return
+		}
+
+		before := append([]model.OrganizationMember(nil), org.OrganizationMembers...)
+		for _, operation := range patch.Operations {
+			if detail := h.applySCIMGroupOperation(ctx, org, operation); detail != "" {
+				scimError(c, http.StatusBadRequest, "invalidValue", detail)
+				return
+			}
+		}
+		h.scimSaveGroup(ctx, c, org, before)
+	}
+}
+
+// SCIMDeleteGroup deletes the organization behind a group.
+func (h *Handler) SCIMDeleteGroup() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		org := h.scimLoadGroup(ctx, c)
+		if org == nil {
+			return
+		}
+		if err := h.deleteOrganization(ctx, org.OrganizationId); err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to delete group")
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// deprovisionUser removes the user from every organization, disables the account and revokes all its credentials.
+func (h *Handler) deprovisionUser(ctx context.Context, user *model.User) error {
+	if err := h.Store.RemoveMemberFromAllOrganizations(ctx, user.Email); err != nil {
+		return err
+	}
+	return h.setUserStatus(ctx, user, model.UserStatusDisabled)
+}
+
+// setSCIMActive maps the SCIM active attribute onto the account state. Deactivating disables the account.
+// Activating re-enables a disabled account but leaves a suspension in place, since suspensions are an
+// administrator's hold that provisioning runs shouldn't lift.
+func setSCIMActive(user *model.User, active bool) {
+	switch {
+	case !active:
+		user.Status = model.UserStatusDisabled
+	case user.AccountStatus() == model.UserStatusDisabled:
+		user.Status = model.UserStatusActive
+	}
+}
+
+// scimSaveUser stores the updated user, renames its memberships when the email changed and deprovisions it
+// when it was deactivated. It writes an error response and returns false on failure.
+func (h *Handler) scimSaveUser(ctx context.Context, c *gin.Context, current, updated *model.User) bool {
+	if updated.Email != current.Email {
+		existing, err := h.Store.GetUserByEmail(ctx, updated.Email)
+		if err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to check user existence")
+			return false
+		}
+		if existing != nil {
+			scimError(c, http.StatusConflict, "uniqueness", "User with this userName already exists")
+			return false
+		}
+	}
+
+	if err := h.Store.UpdateUser(ctx, updated); err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to update user")
+		return false
+	}
+	if updated.Email != current.Email {
+		if err := h.Store.RenameMemberEmail(ctx, current.Email, updated.Email); err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to update memberships")
+			return false
+		}
+	}
+	if updated.AccountStatus() == model.UserStatusDisabled && current.AccountStatus() != model.UserStatusDisabled {
+		if err := h.deprovisionUser(ctx, updated); err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to deprovision user")
+			return false
+		}
+	}
+	return true
+}
+
+// applySCIMUserAttribute sets a user attribute from a PATCH operation. A nil value clears it.
+// It returns a description of the problem if the attribute can't be changed.
+func applySCIMUserAttribute(user *model.User, path string, value interface{}) string {
+	text, _ := value.(string)
+	switch strings.ToLower(path) {
+	case "active":
+		active, ok := value.(bool)
+		if !ok {
+			// Some clients send booleans as strings
+			active, ok = text == "true", text == "true" || text == "false"
+		}
+		if !ok {
+			return "active must be a boolean"
+		}
+		setSCIMActive(user, active)
+	case "username":
+		user.Email = strings.ToLower(text)
+	case "displayname", "name.formatted":
+		if text != "" {
+			user.Name = text
+		}
+	case "externalid":
+		user.ExternalId = text
+	case "emails[type eq \"work\"].value", "emails[primary eq true].value":
+		user.Email = strings.ToLower(text)
+	default:
+		return "Unsupported attribute: " + path
+	}
+	return ""
+}
+
+// applySCIMGroupOperation applies a PATCH operation to an organization.
+// It returns a description of the problem if the operation is not supported.
+func (h *Handler) applySCIMGroupOperation(ctx context.Context, org *model.Organization, operation util.SCIMPatchOperation) string {
+	op := strings.ToLower(operation.Op)
+	path := strings.TrimSpace(operation.Path)
+
+	if memberID, ok := util.ParseSCIMMemberPath(path); ok && op == "remove" {
+		return h.removeSCIMGroupMembers(ctx, org, []scimMemberInput{{Value: memberID}})
+	}
+
+	switch {
+	case strings.EqualFold(path, "displayName") && op != "remove":
+		name, ok := operation.Value.(string)
+		if !ok || name == "" {
+			return "displayName must be a non-empty string"
+		}
+		org.Name = name
+		return ""
+	case path == "" && op == "replace":
+		object, ok := operation.Value.(map[string]interface{})
+		if !ok {
+			return "Operation without a path needs an object value"
+		}
+		if name, ok := object["displayName"].(string); ok && name != "" {
+			org.Name = name
+		}
+		if rawMembers, ok := object["members"]; ok {
+			members, err := decodeSCIMMembers(rawMembers)
+			if err != nil {
+				return err.Error()
+			}
+			return h.setSCIMGroupMembers(ctx, org, members)
+		}
+		return ""
+	case strings.EqualFold(path, "members"):
+		var members []scimMemberInput
+		if operation.Value != nil {
+			var err error
+			if members, err = decodeSCIMMembers(operation.Value); err != nil {
+				return err.Error()
+			}
+		}
+		switch op {
+		case "add":
+			return h.addSCIMGroupMembers(ctx, org, members)
+		case "replace":
+			return h.setSCIMGroupMembers(ctx, org, members)
+		case "remove":
+			if operation.Value == nil {
+				return h.setSCIMGroupMembers(ctx, org, nil)
+			}
+			return h.removeSCIMGroupMembers(ctx, org, members)
+		}
+	}
+	return "Unsupported operation: " + operation.Op + " " + operation.Path
+}
+
+func decodeSCIMMembers(value interface{}) ([]scimMemberInput, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var members []scimMemberInput
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// addSCIMGroupMembers adds users to the organization with the member access level.
+func (h *Handler) addSCIMGroupMembers(ctx context.Context, org *model.Organization, members []scimMemberInput) string {
+	for _, member := range members {
+		user, err := h.Store.GetUserByID(ctx, member.Value)
+		if err != nil || user == nil {
+			return "Unknown user: " + member.Value
+		}
+		if org.FindMember(user.Email) != nil {
+			continue
+		}
+		org.OrganizationMembers = append(org.OrganizationMembers, model.OrganizationMember{
+			Name:        user.Name,
+			UserEmail:   user.Email,
+			AccessLevel: model.AccessLevelMember,
+			Type:        model.MemberTypeUser,
+		})
+	}
+	return ""
+}
+
+// removeSCIMGroupMembers removes users from the organization. Founders stay, so the organization keeps an
+// owner; ownership is transferred through the admin API.
+func (h *Handler) removeSCIMGroupMembers(ctx context.Context, org *model.Organization, members []scimMemberInput) string {
+	for _, member := range members {
+		user, err := h.Store.GetUserByID(ctx, member.Value)
+		if err != nil || user == nil {
+			return "Unknown user: " + member.Value
+		}
+		if existing := org.FindMember(user.Email); existing != nil && existing.AccessLevel != model.AccessLevelFounder {
+			org.RemoveMember(user.Email)
+		}
+	}
+	return ""
+}
+
+// setSCIMGroupMembers replaces the user members of the organization, keeping the access level of users who
+// stay, every Founder and every service account.
+func (h *Handler) setSCIMGroupMembers(ctx context.Context, org *model.Organization, members []scimMemberInput) string {
+	current := org.OrganizationMembers
+	org.OrganizationMembers = nil
+	for _, member := range current {
+		if member.Type == model.MemberTypeServiceAccount || member.AccessLevel == model.AccessLevelFounder {
+			org.OrganizationMembers = append(org.OrganizationMembers, member)
+		}
+	}
+
+	for _, input := range members {
+		user, err := h.Store.GetUserByID(ctx, input.Value)
+		if err != nil || user == nil {
+			return "Unknown user: " + input.Value
+		}
+		if org.FindMember(user.Email) != nil {
+			continue
+		}
+		member := model.OrganizationMember{
+			Name:        user.Name,
+			UserEmail:   user.Email,
+			AccessLevel: model.AccessLevelMember,
+			Type:        model.MemberTypeUser,
+		}
+		previous := model.Organization{OrganizationMembers: current}
+		if existing := previous.FindMember(user.Email); existing != nil {
+			member.AccessLevel = existing.AccessLevel
+		}
+		org.OrganizationMembers = append(org.OrganizationMembers, member)
+	}
+	return ""
+}
+
+// scimSaveGroup stores the group's name and writes the difference between the members before and after the
+// operations as single-member updates, so members who joined through another path meanwhile are kept. It
+// responds with the group as stored.
+func (h *Handler) scimSaveGroup(ctx context.Context, c *gin.Context, org *model.Organization, before []model.OrganizationMember) {
+	if err := h.Store.UpdateOrganization(ctx, org); err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to update group")
+		return
+	}
+	previous := model.Organization{OrganizationMembers: before}
+	for _, member := range before {
+		if org.FindMember(member.UserEmail) != nil {
+			continue
+		}
+		if _, err := h.Store.RemoveOrganizationMember(ctx, org.OrganizationId, member.UserEmail); err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to update group members")
+			return
+		}
+	}
+	for _, member := range org.OrganizationMembers {
+		if previous.FindMember(member.UserEmail) != nil {
+			continue
+		}
+		if _, err := h.Store.AddOrganizationMember(ctx, org.OrganizationId, member); err != nil {
+			scimError(c, http.StatusInternalServerError, "", "Failed to update group members")
+			return
+		}
+	}
+
+	stored, err := h.Store.GetOrganizationByID(ctx, org.OrganizationId)
+	if err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to retrieve group")
+		return
+	}
+	if stored == nil {
+		scimError(c, http.StatusNotFound, "", "Group not found")
+		return
+	}
+	h.scimRespondGroup(ctx, c, http.StatusOK, stored)
+}
+
+func (h *Handler) scimLoadUser(ctx context.Context, c *gin.Context) *model.User {
+	user, err := h.Store.GetUserByID(ctx, c.Param("id"))
+	if err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to retrieve user")
+		return nil
+	}
+	if user == nil {
+		scimError(c, http.StatusNotFound, "", "User not found")
+		return nil
+	}
+	return user
+}
+
+func (h *Handler) scimLoadGroup(ctx context.Context, c *gin.Context) *model.Organization {
+	org, err := h.Store.GetOrganizationByID(ctx, c.Param("id"))
+	if err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to retrieve group")
+		return nil
+	}
+	if org == nil {
+		scimError(c, http.StatusNotFound, "", "Group not found")
+		return nil
+	}
+	return org
+}
+
+func (h *Handler) scimRespondGroup(ctx context.Context, c *gin.Context, status int, org *model.Organization) {
+	resource, err := h.scimGroupResource(ctx, org)
+	if err != nil {
+		scimError(c, http.StatusInternalServerError, "", "Failed to retrieve group members")
+		return
+	}
+	scimJSON(c, status, resource)
+}
+
+func scimUserResource(user *model.User) gin.H {
+	id := user.Id.Hex()
+	resource := gin.H{
+		"schemas":     []string{util.SCIMUserSchema},
+		"id":          id,
+		"userName":    user.Email,
+		"displayName": user.Name,
+		"name":        gin.H{"formatted": user.Name},
+		"emails":      []gin.H{{"value": user.Email, "primary": true}},
+		"active":      user.IsActive(),
+		"meta": gin.H{
+			"resourceType": "User",
+			"location":     "/scim/v2/Users/" + id,
+		},
+	}
+	if user.ExternalId != "" {
+		resource["externalId"] = user.ExternalId
+	}
+	return resource
+}
+
+func (h *Handler) scimGroupResource(ctx context.Context, org *model.Organization) (gin.H, error) {
+	var emails []string
+	for _, member := range org.OrganizationMembers {
+		if member.Type != model.MemberTypeServiceAccount {
+			emails = append(emails, member.UserEmail)
+		}
+	}
+
+	members := []gin.H{}
+	if len(emails) > 0 {
+		users, _, err := h.Store.FindUsers(ctx, bson.M{"email": bson.M{"$in": emails}}, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			members = append(members, gin.H{"value": user.Id.Hex(), "display": user.Email})
+		}
+	}
+
+	return gin.H{
+		"schemas":     []string{util.SCIMGroupSchema},
+		"id":          org.OrganizationId,
+		"displayName": org.Name,
+		"members":     members,
+		"meta": gin.H{
+			"resourceType": "Group",
+			"location":     "/scim/v2/Groups/" + org.OrganizationId,
+		},
+	}, nil
+}
+
+func scimListResponse(resources []gin.H, total, startIndex int64) gin.H {
+	return gin.H{
+		"schemas":      []string{util.SCIMListResponseSchema},
+		"totalResults": total,
+		"startIndex":   startIndex,
+		"itemsPerPage": len(resources),
+		"Resources":    resources,
+	}
+}
+
+// scimQueryFilter turns the filter query parameter into a Mongo filter using the attribute to field mapping.
+// It writes an error response and returns false when the filter is not supported.
+func scimQueryFilter(c *gin.Context, fields map[string]string) (bson.M, bool) {
+	filter, err := util.ParseSCIMFilter(c.Query("filter"))
+	if err != nil {
+		scimError(c, http.StatusBadRequest, "invalidFilter", err.Error())
+		return nil, false
+	}
+	if filter == nil {
+		return bson.M{}, true
+	}
+	field, ok := fields[strings.ToLower(filter.Attribute)]
+	if !ok {
+		scimError(c, http.StatusBadRequest, "invalidFilter", "Filtering on "+filter.Attribute+" is not supported")
+		return nil, false
+	}
+	return bson.M{field: bson.M{"$regex": filter.Pattern()}}, true
+}
+
+// scimPagination reads the 1-based startIndex and count query parameters. A count of 0 asks for the number
+// of matches only (RFC 7644 section 3.4.2.4); callers must not pass it on as a limit, where 0 means none.
+func scimPagination(c *gin.Context) (int64, int64) {
+	startIndex, err := strconv.ParseInt(c.Query("startIndex"), 10, 64)
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+	count, err := strconv.ParseInt(c.Query("count"), 10, 64)
+	if err != nil || count < 0 {
+		count = scimDefaultCount
+	}
+	if count > scimMaxCount {
+		count = scimMaxCount
+	}
+	return startIndex, count
+}
+
+func scimJSON(c *gin.Context, status int, body interface{}) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(status, util.SCIMContentType, payload)
+}
+
+func scimError(c *gin.Context, status int, scimType, detail string) {
+	body := gin.H{
+		"schemas": []string{util.SCIMErrorSchema},
+		"status":  strconv.Itoa(status),
+		"detail":  detail,
+	}
+	if scimType != "" {
+		body["scimType"] = scimType
+	}
+	scimJSON(c, status, body)
+}
@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+	util "organization_management/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const serviceAccountTokenLifespan = 15 * time.Minute
+
+// ServiceAccountInput represents the input data for creating or updating a service account
+type ServiceAccountInput struct {
+	Name        string `json:"name"`
+	AccessLevel string `json:"access_level" binding:"required"`
+}
+
+// serviceAccountScopes maps the access level of a service account to the scopes its tokens carry.
+func serviceAccountScopes(accessLevel string) []string {
+	if model.CanManageOrganization(accessLevel) {
+		return []string{util.ScopeOrganizationsRead, util.ScopeOrganizationsWrite}
+	}
+	return []string{util.ScopeOrganizationsRead}
+}
+
+func validServiceAccountAccessLevel(accessLevel string) bool {
+	return accessLevel == model.AccessLevelAdmin || accessLevel == model.AccessLevelMember
+}
+
+// CreateServiceAccount creates a service account in the organization and lists it among its members.
+// The client secret is only returned once.
+func (h *Handler) CreateServiceAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		org := h.authorizeOrganizationManager(ctx, c, orgID)
+		if org == nil {
+			return
+		}
+
+		var input ServiceAccountInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if input.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+			return
+		}
+		if !validServiceAccountAccessLevel(input.AccessLevel) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "access_level must be admin or member"})
+			return
+		}
+
+		clientID, clientSecret, hashedSecret, err := util.GenerateClientCredentials("sa_")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate client credentials"})
+			return
+		}
+
+		account := model.ServiceAccount{
+			ClientId:       clientID,
+			OrganizationId: orgID,
+			Name:           input.Name,
+			AccessLevel:    input.AccessLevel,
+			HashedSecret:   hashedSecret,
+			CreatedAt:      time.Now(),
+		}
+		if err := h.Store.InsertServiceAccount(ctx, account); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save service account"})
+			return
+		}
+
+		// List the service account alongside the organization's people
+		if _, err := h.Store.AddOrganizationMember(ctx, orgID, model.OrganizationMember{
+			Name:        account.Name,
+			UserEmail:   account.Email(),
+			AccessLevel: account.AccessLevel,
+			Type:        model.MemberTypeServiceAccount,
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"client_id":     account.ClientId,
+			"client_secret": clientSecret,
+			"name":          account.Name,
+			"access_level":  account.AccessLevel,
+			"message":       "Store this secret now, it won't be shown again",
+		})
+	}
+}
+
+// ListServiceAccounts lists the service accounts of the organization.
+func (h *Handler) ListServiceAccounts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		if org := h.authorizeOrganizationManager(ctx, c, orgID); org == nil {
+			return
+		}
+
+		accounts, err := h.Store.GetServiceAccountsByOrganizationID(ctx, orgID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve service accounts"})
+			return
+		}
+
+		c.JSON(http.StatusOK, accounts)
+	}
+}
+
+// UpdateServiceAccount assigns a new access level to a service account.
+func (h *Handler) UpdateServiceAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		org := h.authorizeOrganizationManager(ctx, c, orgID)
+		if org == nil {
+			return
+		}
+
+		account, err := h.Store.GetServiceAccountByClientID(ctx, c.Param("client_id"))
+		if err != nil || account == nil || account.OrganizationId != orgID || account.Disabled {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+			return
+		}
+
+		var input ServiceAccountInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !validServiceAccountAccessLevel(input.AccessLevel) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "access_level must be admin or member"})
+			return
+		}
+
+		if err := h.Store.UpdateServiceAccountAccessLevel(ctx, account.ClientId, input.AccessLevel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service account"})
+			return
+		}
+		if _, err := h.Store.SetMemberAccessLevel(ctx, orgID, account.Email(), input.AccessLevel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"client_id":    account.ClientId,
+			"name":         account.Name,
+			"access_level": input.AccessLevel,
+		})
+	}
+}
+
+// DeleteServiceAccount disables a service account and removes it from the organization's members.
+func (h *Handler) DeleteServiceAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		orgID := c.Param("organization_id")
+		org := h.authorizeOrganizationManager(ctx, c, orgID)
+		if org == nil {
+			return
+		}
+
+		account, err := h.Store.GetServiceAccountByClientID(ctx, c.Param("client_id"))
+		if err != nil || account == nil || account.OrganizationId != orgID {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+			return
+		}
+
+		if err := h.Store.DisableServiceAccount(ctx, account.ClientId); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable service account"})
+			return
+		}
+		if _, err := h.Store.RemoveOrganizationMember(ctx, orgID, account.Email()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Service account disabled successfully"})
+	}
+}
+
+// ServiceAccountTokenInput represents a client credentials token request
+type ServiceAccountTokenInput struct {
+	GrantType    string `json:"grant_type" form:"grant_type"`
+	ClientId     string `json:"client_id" form:"client_id" binding:"required"`
+	ClientSecret string `json:"client_secret" form:"client_secret" binding:"required"`
+}
+
+// IssueServiceAccountToken exchanges a service account's client credentials for a short-lived access token.
+func (h *Handler) IssueServiceAccountToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := requestContext(c, 10*time.Second)
+		defer cancel()
+
+		var input ServiceAccountTokenInput
+		if err := c.ShouldBind(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+			return
+		}
+		if input.GrantType != "" && input.GrantType != "client_credentials" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+			return
+		}
+
+		account, err := h.Store.GetServiceAccountByClientID(ctx, input.ClientId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+			return
+		}
+		if account == nil || account.Disabled || !util.ClientSecretMatches(input.ClientSecret, account.HashedSecret) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+			return
+		}
+
+		token, err := h.Tokens.GenerateServiceAccountToken(account.ClientId, account.Email(), account.OrganizationId,
+			serviceAccountScopes(account.AccessLevel), serviceAccountTokenLifespan)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"access_token": token,
+			"token_type":   "Bearer",
+			"expires_in":   int(serviceAccountTokenLifespan.Seconds()),
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package command
+
+import "go.mongodb.org/mongo-driver/event"
+
+// Key identifies an in-flight MongoDB command across its started and finished events. The driver numbers
+// requests per connection, so the request id alone can collide between commands on different connections.
+type Key struct {
+	ConnectionID string
+	RequestID    int64
+}
+
+// StartedKey returns the key of the command that evt starts.
+func StartedKey(evt *event.CommandStartedEvent) Key {
+	return Key{ConnectionID: evt.ConnectionID, RequestID: evt.RequestID}
+}
+
+// FinishedKey returns the key of the command that evt finishes, whether it succeeded or failed.
+func FinishedKey(evt event.CommandFinishedEvent) Key {
+	return Key{ConnectionID: evt.ConnectionID, RequestID: evt.RequestID}
+}
@@ -1,41 +1,50 @@
 package database
 
 import (
-    util "organization_management/pkg/utils"
+    "organization_management/pkg/config"
+    "organization_management/pkg/metrics"
+    "organization_management/pkg/tracing"
     "context"
     "fmt"
-    "log"
-    "time"
+    "go.mongodb.org/mongo-driver/event"
     "go.mongodb.org/mongo-driver/mongo"
     "go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func ConnectDB() *mongo.Client  {
-    client, err := mongo.NewClient(options.Client().ApplyURI(util.EnvMongoURI()))
+// ConnectDB connects to MongoDB with cfg and pings it, so an unreachable server is reported at startup.
+func ConnectDB(ctx context.Context, cfg config.MongoConfig) (*mongo.Client, error) {
+    // every command is timed for the metrics and traced as a child of the caller's span
+    monitor := combineMonitors(metrics.MongoMonitor(), tracing.MongoMonitor())
+    client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.URI).SetMonitor(monitor))
     if err != nil {
-        log.Fatal(err)
-    }
-  
-    ctx, _ := context.WithTimeout(context.Background(), 10*time.Second)
-    err = client.Connect(ctx)
-    if err != nil {
-        log.Fatal(err)
+        return nil, fmt.Errorf("connecting to MongoDB: %w", err)
     }
 
     //ping the database
-    err = client.Ping(ctx, nil)
-    if err != nil {
-        log.Fatal(err)
+    if err := client.Ping(ctx, nil); err != nil {
+        client.Disconnect(context.Background())
+        return nil, fmt.Errorf("pinging MongoDB: %w", err)
     }
-    fmt.Println("Connected to MongoDB")
-    return client
+    return client, nil
 }
 
-//Client instance
-var DB *mongo.Client = ConnectDB()
-
-//getting database collections
-func GetCollection(client *mongo.Client, collectionName string) *mongo.Collection {
-    collection := client.Database(util.EnvDatabaseNameMongoDB()).Collection(collectionName)
-    return collection
-}
\ No newline at end of file
+// combineMonitors lets several command monitors watch the same client, which accepts only one.
+func combineMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+    return &event.CommandMonitor{
+        Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+            for _, monitor := range monitors {
+                monitor.Started(ctx, evt)
+            }
+        },
+        Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+            for _, monitor := range monitors {
+                monitor.Succeeded(ctx, evt)
+            }
+        },
+        Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+            for _, monitor := range monitors {
+                monitor.Failed(ctx, evt)
+            }
+        },
+    }
+}
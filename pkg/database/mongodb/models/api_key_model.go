@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey is a personal credential a user creates for scripts and CI jobs.
+// Only the SHA-256 hash of the key is stored; the key itself is shown once on creation.
+type APIKey struct {
+	Id         primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	KeyId      string             `json:"key_id" bson:"key_id"`
+	UserId     string             `json:"-" bson:"user_id"`
+	UserEmail  string             `json:"-" bson:"user_email"`
+	Name       string             `json:"name" bson:"name"`
+	Scopes     []string           `json:"scopes" bson:"scopes"`
+	HashedKey  string             `json:"-" bson:"hashed_key"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	ExpiresAt  time.Time          `json:"expires_at" bson:"expires_at"`
+	LastUsedAt *time.Time         `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	RevokedAt  *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// IsActive reports whether the key can still be used to authenticate.
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && now.Before(k.ExpiresAt)
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Actions recorded in the audit trail.
+const (
+	AuditUserDisabled         = "user.disabled"
+	AuditUserSuspended        = "user.suspended"
+	AuditUserEnabled          = "user.enabled"
+	AuditUserUnlocked         = "user.unlocked"
+	AuditUserPasswordReset    = "user.password_reset_forced"
+	AuditUserPlatformRole     = "user.platform_role_changed"
+	AuditOrganizationTransfer = "organization.ownership_transferred"
+	AuditOrganizationImported = "organization.imported"
+	AuditUserImpersonated     = "user.impersonation_started"
+	AuditImpersonatedRequest  = "user.impersonated_request"
+)
+
+// Kinds of records an audit event can target.
+const (
+	AuditTargetUser         = "user"
+	AuditTargetOrganization = "organization"
+)
+
+// AuditEvent records an administrative action: who did what to which record, and when.
+type AuditEvent struct {
+	Id         primitive.ObjectID     `json:"-" bson:"_id,omitempty"`
+	EventId    string                 `json:"event_id" bson:"event_id"`
+	ActorId    string                 `json:"actor_id" bson:"actor_id"`
+	ActorEmail string                 `json:"actor_email" bson:"actor_email"`
+	Action     string                 `json:"action" bson:"action"`
+	TargetType string                 `json:"target_type" bson:"target_type"`
+	TargetId   string                 `json:"target_id" bson:"target_id"`
+	Details    map[string]interface{} `json:"details,omitempty" bson:"details,omitempty"`
+	ClientIP   string                 `json:"client_ip" bson:"client_ip"`
+	CreatedAt  time.Time              `json:"created_at" bson:"created_at"`
+	// Imported marks events copied from another deployment's audit trail by an organization import
+	Imported bool `json:"imported,omitempty" bson:"imported,omitempty"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InviteLink lets any signed-in user who has its token join an organization at a set access level.
+// Only the SHA-256 hash of the token is stored; the token itself is shown once on creation.
+type InviteLink struct {
+	Id             primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	LinkId         string             `json:"link_id" bson:"link_id"`
+	OrganizationId string             `json:"organization_id" bson:"organization_id"`
+	HashedToken    string             `json:"-" bson:"hashed_token"`
+	AccessLevel    string             `json:"access_level" bson:"access_level"`
+	MaxUses        int                `json:"max_uses" bson:"max_uses"`
+	Uses           int                `json:"uses" bson:"uses"`
+	CreatedBy      string             `json:"created_by" bson:"created_by"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	ExpiresAt      time.Time          `json:"expires_at" bson:"expires_at"`
+	RevokedAt      *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// IsActive reports whether the link can still be redeemed. A MaxUses of zero means unlimited uses.
+func (l *InviteLink) IsActive(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt) && (l.MaxUses == 0 || l.Uses < l.MaxUses)
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Statuses a join request moves through. Only pending requests can be decided.
+const (
+	JoinRequestPending  = "pending"
+	JoinRequestApproved = "approved"
+	JoinRequestRejected = "rejected"
+)
+
+// JoinRequest is a user's request to become a member of a discoverable organization.
+type JoinRequest struct {
+	Id             primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	RequestId      string             `json:"request_id" bson:"request_id"`
+	OrganizationId string             `json:"organization_id" bson:"organization_id"`
+	UserId         string             `json:"user_id" bson:"user_id"`
+	UserEmail      string             `json:"user_email" bson:"user_email"`
+	UserName       string             `json:"user_name" bson:"user_name"`
+	Message        string             `json:"message" bson:"message"`
+	Status         string             `json:"status" bson:"status"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	DecidedBy      string             `json:"decided_by,omitempty" bson:"decided_by,omitempty"`
+	DecidedAt      *time.Time         `json:"decided_at,omitempty" bson:"decided_at,omitempty"`
+}
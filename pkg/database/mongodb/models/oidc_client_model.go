@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OIDCClient is an application registered to sign users in through this service.
+// Public clients have no secret and must rely on PKCE alone.
+type OIDCClient struct {
+	Id           primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	ClientId     string             `json:"client_id" bson:"client_id"`
+	Name         string             `json:"name" bson:"name"`
+	RedirectURIs []string           `json:"redirect_uris" bson:"redirect_uris"`
+	Public       bool               `json:"public" bson:"public"`
+	HashedSecret string             `json:"-" bson:"hashed_secret,omitempty"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// AllowsRedirectURI reports whether uri exactly matches one of the registered redirect URIs.
+func (c *OIDCClient) AllowsRedirectURI(uri string) bool {
+	for _, registered := range c.RedirectURIs {
+		if registered == uri {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrganizationDomain is an email domain claimed by an organization. Once verified, users with an email in
+// the domain can join the organization automatically.
+type OrganizationDomain struct {
+	Id                 primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	OrganizationId     string             `json:"organization_id" bson:"organization_id"`
+	Domain             string             `json:"domain" bson:"domain"`
+	VerificationToken  string             `json:"verification_token" bson:"verification_token"`
+	Verified           bool               `json:"verified" bson:"verified"`
+	AutoJoin           bool               `json:"auto_join" bson:"auto_join"`
+	DefaultAccessLevel string             `json:"default_access_level" bson:"default_access_level"`
+	CreatedAt          time.Time          `json:"created_at" bson:"created_at"`
+	VerifiedAt         *time.Time         `json:"verified_at,omitempty" bson:"verified_at,omitempty"`
+}
@@ -1,19 +1,102 @@
 package model
 
 import (
+	"strings"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Access levels a member can hold in an organization.
+const (
+	AccessLevelFounder = "Founder"
+	AccessLevelAdmin   = "admin"
+	AccessLevelMember  = "member"
+)
+
+// Member types distinguishing people from service accounts in OrganizationMembers.
+const (
+	MemberTypeUser           = "user"
+	MemberTypeServiceAccount = "service_account"
+)
+
 type Organization struct {
     Id                   primitive.ObjectID   `json:"id,omitempty" bson:"_id,omitempty"`
 	OrganizationId		 string               `json:"organization_id,omitempty"`
     Name                 string               `json:"name" validate:"required"`
     Description          string               `json:"description" validate:"required"`
     OrganizationMembers  []OrganizationMember `json:"organization_members"`
+    Discoverable         bool                 `json:"discoverable"`
 }
 
 type OrganizationMember struct {
     Name        string `json:"name" validate:"required"`
     UserEmail       string `json:"email" validate:"required"`
     AccessLevel string `json:"access_level" validate:"required"`
+    Type        string `json:"type,omitempty"`
+    // Status is the member's account state, filled in for responses and never stored
+    Status      string `json:"status,omitempty" bson:"-"`
+}
+
+// FindMember returns the member with the given email, or nil if there is none.
+func (o *Organization) FindMember(email string) *OrganizationMember {
+	for i := range o.OrganizationMembers {
+		if strings.EqualFold(o.OrganizationMembers[i].UserEmail, email) {
+			return &o.OrganizationMembers[i]
+		}
+	}
+	return nil
+}
+
+// RemoveMember drops the member with the given email and reports whether one was removed.
+func (o *Organization) RemoveMember(email string) bool {
+	for i := range o.OrganizationMembers {
+		if strings.EqualFold(o.OrganizationMembers[i].UserEmail, email) {
+			o.OrganizationMembers = append(o.OrganizationMembers[:i], o.OrganizationMembers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// IsSoleFounder reports whether the member with the given email is the organization's only Founder.
+func (o *Organization) IsSoleFounder(email string) bool {
+	member := o.FindMember(email)
+	if member == nil || member.AccessLevel != AccessLevelFounder {
+		return false
+	}
+	for _, other := range o.OrganizationMembers {
+		if other.AccessLevel == AccessLevelFounder && !strings.EqualFold(other.UserEmail, email) {
+			return false
+		}
+	}
+	return true
+}
+
+// Successor picks who takes over as Founder when the member with the given email leaves: the first other
+// admin, otherwise the first other member. Service accounts never qualify. It returns nil if nobody is left.
+func (o *Organization) Successor(email string) *OrganizationMember {
+	var fallback *OrganizationMember
+	for i := range o.OrganizationMembers {
+		candidate := &o.OrganizationMembers[i]
+		if strings.EqualFold(candidate.UserEmail, email) || candidate.Type == MemberTypeServiceAccount {
+			continue
+		}
+		if candidate.AccessLevel == AccessLevelAdmin {
+			return candidate
+		}
+		if fallback == nil {
+			fallback = candidate
+		}
+	}
+	return fallback
+}
+
+// IsValidAccessLevel reports whether level can be assigned to a member.
+func IsValidAccessLevel(level string) bool {
+	return level == AccessLevelFounder || level == AccessLevelAdmin || level == AccessLevelMember
+}
+
+// CanManageOrganization reports whether a member with the access level may administer the organization.
+func CanManageOrganization(level string) bool {
+	return level == AccessLevelFounder || level == AccessLevelAdmin
 }
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ServiceAccount is a non-human identity that belongs to an organization and
+// authenticates with client credentials.
+type ServiceAccount struct {
+	Id             primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	ClientId       string             `json:"client_id" bson:"client_id"`
+	OrganizationId string             `json:"organization_id" bson:"organization_id"`
+	Name           string             `json:"name" bson:"name"`
+	AccessLevel    string             `json:"access_level" bson:"access_level"`
+	HashedSecret   string             `json:"-" bson:"hashed_secret"`
+	Disabled       bool               `json:"disabled" bson:"disabled"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	DisabledAt     *time.Time         `json:"disabled_at,omitempty" bson:"disabled_at,omitempty"`
+}
+
+// Email is the address the service account is listed under in OrganizationMembers.
+func (s *ServiceAccount) Email() string {
+	return s.ClientId + "@service-accounts.local"
+}
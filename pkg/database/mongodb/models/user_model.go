@@ -2,35 +2,83 @@ package model
 
 import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"golang.org/x/crypto/bcrypt"
+	util "organization_management/pkg/utils"
 
 	"regexp"
+	"time"
 )
 
+// Account states. Suspended and disabled users can't sign in or use any of their tokens: suspension is a
+// temporary hold that keeps memberships, disabling locks out a departed user for good.
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusDisabled  = "disabled"
+)
+
+// PlatformRoleAdmin is the platform role of operators who manage every user and organization.
+// It is unrelated to the access level a user holds inside an organization.
+const PlatformRoleAdmin = "admin"
+
 // User represents a user in the database.
 type User struct {
-	Id       primitive.ObjectID `json:"id,omitempty"`
-	Name     string             `json:"name,omitempty" validate:"required"`
-	Email    string             `json:"email,omitempty" validate:"required"`
-	Password string             `json:"password,omitempty" validate:"required"`
+	Id            primitive.ObjectID  `json:"id,omitempty"`
+	Name          string              `json:"name,omitempty" validate:"required"`
+	Email         string              `json:"email,omitempty" validate:"required"`
+	Password      string              `json:"password,omitempty" validate:"required"`
+	Identities    []FederatedIdentity `json:"-"`
+	ExternalId    string              `json:"-"`
+	Status        string              `json:"-"`
+	PlatformRole  string              `json:"-"`
+	PasswordReset *PasswordReset      `json:"-"`
+}
+
+// PasswordReset is a pending password reset. Until it is redeemed the user can't sign in with a password.
+type PasswordReset struct {
+	HashedToken string    `json:"-"`
+	ExpiresAt   time.Time `json:"-"`
+}
+
+// AccountStatus returns the user's state. Users stored before states existed are active.
+func (u *User) AccountStatus() string {
+	if u.Status == "" {
+		return UserStatusActive
+	}
+	return u.Status
+}
+
+// IsActive reports whether the user may sign in and use their tokens.
+func (u *User) IsActive() bool {
+	return u.AccountStatus() == UserStatusActive
+}
+
+// IsValidPlatformRole reports whether role can be assigned to a user. The empty role is an ordinary user.
+func IsValidPlatformRole(role string) bool {
+	return role == "" || role == PlatformRoleAdmin
+}
+
+// IsPlatformAdmin reports whether the user holds the platform admin role.
+func (u *User) IsPlatformAdmin() bool {
+	return u.PlatformRole == PlatformRoleAdmin
 }
 
-// HashPassword hashes the user's password using bcrypt.
-func (u *User) HashPassword() error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+// FederatedIdentity links a user to an account at an upstream identity provider.
+type FederatedIdentity struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+}
+
+// HashPassword replaces the user's plain text password with its hash from hashing.
+func (u *User) HashPassword(hashing *util.PasswordHashing) error {
+	hashedPassword, err := hashing.Hash(u.Password)
 	if err != nil {
 		return err
 	}
 
-	u.Password = string(hashedPassword)
+	u.Password = hashedPassword
 	return nil
 }
 
-// VerifyPassword compares the user's password with the hashed password.
-func (u *User) VerifyPassword(password, hashedPassword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-}
-
 // ValidateEmail checks if the provided email address is valid.
 func ValidateEmail(email string) bool {
     regex := `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
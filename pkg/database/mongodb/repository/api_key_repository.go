@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InsertAPIKey stores a new API key.
+func (s *Store) InsertAPIKey(ctx context.Context, key model.APIKey) error {
+	_, err := s.apiKeyCollection.InsertOne(ctx, key)
+	return err
+}
+
+// GetAPIKeyByKeyID retrieves an API key by its public identifier, or nil if it doesn't exist.
+func (s *Store) GetAPIKeyByKeyID(ctx context.Context, keyID string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := s.apiKeyCollection.FindOne(ctx, bson.M{"key_id": keyID}).Decode(&key)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetAPIKeysByUserID retrieves all API keys of a user, newest first.
+func (s *Store) GetAPIKeysByUserID(ctx context.Context, userID string) ([]model.APIKey, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := s.apiKeyCollection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []model.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey marks a user's API key as revoked.
+func (s *Store) RevokeAPIKey(ctx context.Context, userID, keyID string) error {
+	filter := bson.M{"key_id": keyID, "user_id": userID, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+	result, err := s.apiKeyCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("API key not found")
+	}
+	return nil
+}
+
+// RevokeAPIKeysByUserID revokes every active API key of a user.
+func (s *Store) RevokeAPIKeysByUserID(ctx context.Context, userID string) error {
+	filter := bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+	_, err := s.apiKeyCollection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// TouchAPIKey records when the key was last used.
+func (s *Store) TouchAPIKey(ctx context.Context, keyID string) error {
+	_, err := s.apiKeyCollection.UpdateOne(ctx, bson.M{"key_id": keyID}, bson.M{"$set": bson.M{"last_used_at": time.Now()}})
+	return err
+}
+
+// DeleteAPIKeysByUserID removes every API key of a user.
+func (s *Store) DeleteAPIKeysByUserID(ctx context.Context, userID string) error {
+	_, err := s.apiKeyCollection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	model "organization_management/pkg/database/mongodb/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InsertAuditEvent appends an event to the audit trail. Events are never updated or deleted, except for
+// imported events when their import is rolled back.
+func (s *Store) InsertAuditEvent(ctx context.Context, event model.AuditEvent) error {
+	_, err := s.auditEventCollection.InsertOne(ctx, event)
+	return err
+}
+
+// FindAuditEvents retrieves a page of audit events matching filter, newest first, along with the total number of matches.
+func (s *Store) FindAuditEvents(ctx context.Context, filter bson.M, skip, limit int64) ([]model.AuditEvent, int64, error) {
+	total, err := s.auditEventCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).SetSkip(skip).SetLimit(limit)
+	cursor, err := s.auditEventCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []model.AuditEvent{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// DeleteImportedAuditEvents removes the events an organization import copied, when the import is rolled back.
+func (s *Store) DeleteImportedAuditEvents(ctx context.Context, orgID string) error {
+	filter := bson.M{"target_type": model.AuditTargetOrganization, "target_id": orgID, "imported": true}
+	_, err := s.auditEventCollection.DeleteMany(ctx, filter)
+	return err
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Store runs the repository queries against the collections of one database.
+type Store struct {
+	userCollection               *mongo.Collection
+	orgCollection                *mongo.Collection
+	apiKeyCollection             *mongo.Collection
+	auditEventCollection         *mongo.Collection
+	inviteLinkCollection         *mongo.Collection
+	joinRequestCollection        *mongo.Collection
+	oidcClientCollection         *mongo.Collection
+	organizationDomainCollection *mongo.Collection
+	serviceAccountCollection     *mongo.Collection
+}
+
+// NewStore binds the repository queries to the collections of db.
+func NewStore(db *mongo.Database) *Store {
+	return &Store{
+		userCollection:               db.Collection("users"),
+		orgCollection:                db.Collection("organizations"),
+		apiKeyCollection:             db.Collection("api_keys"),
+		auditEventCollection:         db.Collection("audit_events"),
+		inviteLinkCollection:         db.Collection("invite_links"),
+		joinRequestCollection:        db.Collection("join_requests"),
+		oidcClientCollection:         db.Collection("oidc_clients"),
+		organizationDomainCollection: db.Collection("organization_domains"),
+		serviceAccountCollection:     db.Collection("service_accounts"),
+	}
+}
+
+// EnsureIndexes creates the indexes the stored data relies on. Only one organization can hold the verified
+// claim on a domain, so two organizations verifying it at the same time can't both succeed.
+func (s *Store) EnsureIndexes(ctx context.Context) error {
+	_, err := s.organizationDomainCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "domain", Value: 1}},
+		Options: options.Index().
+			SetName("verified_domain_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"verified": true}),
+	})
+	return err
+}
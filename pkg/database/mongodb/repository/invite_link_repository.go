@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InsertInviteLink stores a new invite link.
+func (s *Store) InsertInviteLink(ctx context.Context, link model.InviteLink) error {
+	_, err := s.inviteLinkCollection.InsertOne(ctx, link)
+	return err
+}
+
+// GetInviteLinkByHash retrieves an invite link by the hash of its token, or nil if it doesn't exist.
+func (s *Store) GetInviteLinkByHash(ctx context.Context, hashedToken string) (*model.InviteLink, error) {
+	var link model.InviteLink
+	err := s.inviteLinkCollection.FindOne(ctx, bson.M{"hashed_token": hashedToken}).Decode(&link)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &link, nil
+}
+
+// GetActiveInviteLinksByOrganizationID retrieves the organization's links that can still be redeemed, newest first.
+func (s *Store) GetActiveInviteLinksByOrganizationID(ctx context.Context, orgID string) ([]model.InviteLink, error) {
+	filter := activeInviteLinkFilter(time.Now())
+	filter["organization_id"] = orgID
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := s.inviteLinkCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	links := []model.InviteLink{}
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// ConsumeInviteLink counts one use of the link if it is still active and returns the updated link,
+// or nil if the link is revoked, expired or used up. The check and the increment happen atomically
+// so concurrent redemptions can't exceed MaxUses.
+func (s *Store) ConsumeInviteLink(ctx context.Context, hashedToken string) (*model.InviteLink, error) {
+	filter := activeInviteLinkFilter(time.Now())
+	filter["hashed_token"] = hashedToken
+	update := bson.M{"$inc": bson.M{"uses": 1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var link model.InviteLink
+	err := s.inviteLinkCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&link)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &link, nil
+}
+
+// ReleaseInviteLinkUse gives back a use counted by ConsumeInviteLink when the redemption could not be completed.
+func (s *Store) ReleaseInviteLinkUse(ctx context.Context, linkID string) error {
+	filter := bson.M{"link_id": linkID, "uses": bson.M{"$gt": 0}}
+	_, err := s.inviteLinkCollection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"uses": -1}})
+	return err
+}
+
+// RevokeInviteLink marks an organization's invite link as revoked.
+func (s *Store) RevokeInviteLink(ctx context.Context, orgID, linkID string) error {
+	filter := bson.M{"link_id": linkID, "organization_id": orgID, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+	result, err := s.inviteLinkCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("Invite link not found")
+	}
+	return nil
+}
+
+// RevokeInviteLinksByOrganizationID revokes every invite link of an organization.
+func (s *Store) RevokeInviteLinksByOrganizationID(ctx context.Context, orgID string) error {
+	filter := bson.M{"organization_id": orgID, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+	_, err := s.inviteLinkCollection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+func activeInviteLinkFilter(now time.Time) bson.M {
+	return bson.M{
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+		"$or": bson.A{
+			bson.M{"max_uses": 0},
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$uses", "$max_uses"}}},
+		},
+	}
+}
+
+// GetInviteLinksByOrganizationID retrieves all invite links of an organization, including inactive ones.
+func (s *Store) GetInviteLinksByOrganizationID(ctx context.Context, orgID string) ([]model.InviteLink, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := s.inviteLinkCollection.Find(ctx, bson.M{"organization_id": orgID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	links := []model.InviteLink{}
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// DeleteInviteLinksByOrganizationID removes every invite link of an organization.
+func (s *Store) DeleteInviteLinksByOrganizationID(ctx context.Context, orgID string) error {
+	_, err := s.inviteLinkCollection.DeleteMany(ctx, bson.M{"organization_id": orgID})
+	return err
+}
+
+// GetInviteLinksByCreator retrieves the invite links a user created, newest first.
+func (s *Store) GetInviteLinksByCreator(ctx context.Context, email string) ([]model.InviteLink, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := s.inviteLinkCollection.Find(ctx, bson.M{"created_by": email}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	links := []model.InviteLink{}
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// ClearInviteLinkCreator removes the email of the creator from their invite links, for account deletion.
+func (s *Store) ClearInviteLinkCreator(ctx context.Context, email string) error {
+	_, err := s.inviteLinkCollection.UpdateMany(ctx, bson.M{"created_by": email}, bson.M{"$set": bson.M{"created_by": ""}})
+	return err
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// InsertJoinRequest stores a new join request.
+func (s *Store) InsertJoinRequest(ctx context.Context, request model.JoinRequest) error {
+	_, err := s.joinRequestCollection.InsertOne(ctx, request)
+	return err
+}
+
+// GetJoinRequest retrieves a join request of the organization, or nil if it doesn't exist.
+func (s *Store) GetJoinRequest(ctx context.Context, orgID, requestID string) (*model.JoinRequest, error) {
+	return s.findJoinRequest(ctx, bson.M{"organization_id": orgID, "request_id": requestID})
+}
+
+// GetPendingJoinRequest retrieves the user's pending request to join the organization, or nil if there is none.
+func (s *Store) GetPendingJoinRequest(ctx context.Context, orgID, userID string) (*model.JoinRequest, error) {
+	return s.findJoinRequest(ctx, bson.M{"organization_id": orgID, "user_id": userID, "status": model.JoinRequestPending})
+}
+
+// GetJoinRequestsByOrganizationID retrieves the organization's join requests, newest first.
+// An empty status returns requests in every status.
+func (s *Store) GetJoinRequestsByOrganizationID(ctx context.Context, orgID, status string) ([]model.JoinRequest, error) {
+	filter := bson.M{"organization_id": orgID}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := s.joinRequestCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	requests := []model.JoinRequest{}
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// DecideJoinRequest approves or rejects a pending join request.
+func (s *Store) DecideJoinRequest(ctx context.Context, orgID, requestID, status, decidedBy string) error {
+	filter := bson.M{"organization_id": orgID, "request_id": requestID, "status": model.JoinRequestPending}
+	update := bson.M{"$set": bson.M{"status": status, "decided_by": decidedBy, "decided_at": time.Now()}}
+	result, err := s.joinRequestCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("Pending join request not found")
+	}
+	return nil
+}
+
+// DeleteJoinRequestsByOrganizationID removes every join request of an organization.
+func (s *Store) DeleteJoinRequestsByOrganizationID(ctx context.Context, orgID string) error {
+	_, err := s.joinRequestCollection.DeleteMany(ctx, bson.M{"organization_id": orgID})
+	return err
+}
+
+func (s *Store) findJoinRequest(ctx context.Context, filter bson.M) (*model.JoinRequest, error) {
+	var request model.JoinRequest
+	err := s.joinRequestCollection.FindOne(ctx, filter).Decode(&request)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetJoinRequestsByUserID retrieves every join request a user made, newest first.
+func (s *Store) GetJoinRequestsByUserID(ctx context.Context, userID string) ([]model.JoinRequest, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := s.joinRequestCollection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	requests := []model.JoinRequest{}
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// DeleteJoinRequestsByUserID removes every join request a user made.
+func (s *Store) DeleteJoinRequestsByUserID(ctx context.Context, userID string) error {
+	_, err := s.joinRequestCollection.DeleteMany(ctx, bson.M{"user_id": userID})
+	return err
+}
+
+// ClearJoinRequestDecider removes the email of the person who decided join requests, for account deletion.
+func (s *Store) ClearJoinRequestDecider(ctx context.Context, email string) error {
+	_, err := s.joinRequestCollection.UpdateMany(ctx, bson.M{"decided_by": email}, bson.M{"$unset": bson.M{"decided_by": ""}})
+	return err
+}
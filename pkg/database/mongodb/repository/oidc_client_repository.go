@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	model "organization_management/pkg/database/mongodb/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// InsertOIDCClient registers a new client application.
+func (s *Store) InsertOIDCClient(ctx context.Context, client model.OIDCClient) error {
+	_, err := s.oidcClientCollection.InsertOne(ctx, client)
+	return err
+}
+
+// GetOIDCClientByClientID retrieves a client application, or nil if it isn't registered.
+func (s *Store) GetOIDCClientByClientID(ctx context.Context, clientID string) (*model.OIDCClient, error) {
+	var client model.OIDCClient
+	err := s.oidcClientCollection.FindOne(ctx, bson.M{"client_id": clientID}).Decode(&client)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+// GetAllOIDCClients retrieves every registered client application.
+func (s *Store) GetAllOIDCClients(ctx context.Context) ([]model.OIDCClient, error) {
+	cursor, err := s.oidcClientCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	clients := []model.OIDCClient{}
+	if err := cursor.All(ctx, &clients); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+// DeleteOIDCClient removes a client application.
+func (s *Store) DeleteOIDCClient(ctx context.Context, clientID string) error {
+	result, err := s.oidcClientCollection.DeleteOne(ctx, bson.M{"client_id": clientID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("Client not found")
+	}
+	return nil
+}
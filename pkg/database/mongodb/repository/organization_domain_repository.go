@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrDomainAlreadyVerified is returned when another organization holds the verified claim on the domain.
+var ErrDomainAlreadyVerified = errors.New("Domain is already verified by another organization")
+
+// InsertOrganizationDomain stores a new domain claim. A verified claim fails with ErrDomainAlreadyVerified
+// if another organization verified the domain.
+func (s *Store) InsertOrganizationDomain(ctx context.Context, domain model.OrganizationDomain) error {
+	_, err := s.organizationDomainCollection.InsertOne(ctx, domain)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDomainAlreadyVerified
+	}
+	return err
+}
+
+// GetOrganizationDomain retrieves an organization's claim on a domain, or nil if it doesn't exist.
+func (s *Store) GetOrganizationDomain(ctx context.Context, orgID, domain string) (*model.OrganizationDomain, error) {
+	var claim model.OrganizationDomain
+	err := s.organizationDomainCollection.FindOne(ctx, bson.M{"organization_id": orgID, "domain": domain}).Decode(&claim)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// GetVerifiedOrganizationDomain retrieves the verified claim on a domain, or nil if no organization verified it.
+func (s *Store) GetVerifiedOrganizationDomain(ctx context.Context, domain string) (*model.OrganizationDomain, error) {
+	var claim model.OrganizationDomain
+	err := s.organizationDomainCollection.FindOne(ctx, bson.M{"domain": domain, "verified": true}).Decode(&claim)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// GetOrganizationDomainsByOrganizationID retrieves all domains claimed by an organization.
+func (s *Store) GetOrganizationDomainsByOrganizationID(ctx context.Context, orgID string) ([]model.OrganizationDomain, error) {
+	opts := options.Find().SetSort(bson.M{"domain": 1})
+	cursor, err := s.organizationDomainCollection.Find(ctx, bson.M{"organization_id": orgID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	domains := []model.OrganizationDomain{}
+	if err := cursor.All(ctx, &domains); err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
+// MarkOrganizationDomainVerified records that the organization proved control of the domain. It fails with
+// ErrDomainAlreadyVerified if another organization verified the domain first.
+func (s *Store) MarkOrganizationDomainVerified(ctx context.Context, orgID, domain string) error {
+	filter := bson.M{"organization_id": orgID, "domain": domain}
+	update := bson.M{"$set": bson.M{"verified": true, "verified_at": time.Now()}}
+	_, err := s.organizationDomainCollection.UpdateOne(ctx, filter, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDomainAlreadyVerified
+	}
+	return err
+}
+
+// UpdateOrganizationDomainSettings changes whether matching users join automatically and at which access level.
+func (s *Store) UpdateOrganizationDomainSettings(ctx context.Context, orgID, domain string, autoJoin bool, accessLevel string) error {
+	filter := bson.M{"organization_id": orgID, "domain": domain}
+	update := bson.M{"$set": bson.M{"auto_join": autoJoin, "default_access_level": accessLevel}}
+	_, err := s.organizationDomainCollection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// DeleteOrganizationDomain removes an organization's claim on a domain.
+func (s *Store) DeleteOrganizationDomain(ctx context.Context, orgID, domain string) error {
+	_, err := s.organizationDomainCollection.DeleteOne(ctx, bson.M{"organization_id": orgID, "domain": domain})
+	return err
+}
+
+// DeleteOrganizationDomainsByOrganizationID removes every domain claimed by an organization.
+func (s *Store) DeleteOrganizationDomainsByOrganizationID(ctx context.Context, orgID string) error {
+	_, err := s.organizationDomainCollection.DeleteMany(ctx, bson.M{"organization_id": orgID})
+	return err
+}
@@ -3,24 +3,20 @@ package repository
 import (
     "context"
 	"errors"
+	"regexp"
+	"time"
 
     model "organization_management/pkg/database/mongodb/models"
-    database "organization_management/pkg/database/mongodb"
 
     "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/bson"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-var orgCollection *mongo.Collection
-
-func init() {
-    orgCollection = database.GetCollection(database.DB, "organizations")
-}
-
 // InsertOrganization inserts a new organization into the database.
-func InsertOrganization(ctx context.Context, org model.Organization) (*mongo.InsertOneResult, string, error) {
+func (s *Store) InsertOrganization(ctx context.Context, org model.Organization) (*mongo.InsertOneResult, string, error) {
 	// Generate a UUID for the organization ID
 	organizationID := uuid.New().String()
 
@@ -29,10 +25,11 @@ func InsertOrganization(ctx context.Context, org model.Organization) (*mongo.Ins
         Name:         org.Name,
         Description:  org.Description,
         OrganizationMembers:      org.OrganizationMembers,
+        Discoverable: org.Discoverable,
     }
 
     // Insert the new organization into the database
-	result, err := orgCollection.InsertOne(ctx, newOrg)
+	result, err := s.orgCollection.InsertOne(ctx, newOrg)
 	if err != nil {
 		return nil, "", err
 	}
@@ -40,28 +37,31 @@ func InsertOrganization(ctx context.Context, org model.Organization) (*mongo.Ins
 	return result, organizationID, nil
 }
 
-// GetOrganizationByID retrieves an organization by its ID from the database.
-func GetOrganizationByID(ctx context.Context, id string) (*model.Organization, error) {
+// GetOrganizationByID retrieves an organization by its ID from the database, or nil if it doesn't exist.
+func (s *Store) GetOrganizationByID(ctx context.Context, id string) (*model.Organization, error) {
     // Define a filter to find the organization by ID
     filter := bson.M{"organizationid": id}
 
     // Query the database to find the organization
     var org model.Organization
-    err := orgCollection.FindOne(ctx, filter).Decode(&org)
+    err := s.orgCollection.FindOne(ctx, filter).Decode(&org)
     if err != nil {
-        return nil, err // Organization not found or error occurred
+        if errors.Is(err, mongo.ErrNoDocuments) {
+            return nil, nil
+        }
+        return nil, err
     }
 
     return &org, nil // Return the organization if found
 }
 
 // GetAllOrganizations retrieves all organizations from the database.
-func GetAllOrganizations(ctx context.Context) ([]model.Organization, error) {
+func (s *Store) GetAllOrganizations(ctx context.Context) ([]model.Organization, error) {
 	// Initialize an empty filter to retrieve all documents
 	filter := bson.M{}
 
 	// Retrieve all organizations from the database
-	cursor, err := orgCollection.Find(ctx, filter)
+	cursor, err := s.orgCollection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -76,13 +76,14 @@ func GetAllOrganizations(ctx context.Context) ([]model.Organization, error) {
 	return orgs, nil
 }
 
-// GetOrganizationsByMemberEmail retrieves organizations where the specified user is a member.
-func GetOrganizationsByMemberEmail(ctx context.Context, userEmail string) ([]model.Organization, error) {
+// GetOrganizationsByMemberEmail retrieves organizations where the specified user is a member, matching the
+// email in any case.
+func (s *Store) GetOrganizationsByMemberEmail(ctx context.Context, userEmail string) ([]model.Organization, error) {
 	// Define a filter to find organizations where the user is a member
-	filter := bson.M{"organization_members.email": userEmail}
+	filter := bson.M{"organizationmembers.useremail": emailPattern(userEmail)}
 
 	// Retrieve organizations from the database based on the filter
-	cursor, err := orgCollection.Find(ctx, filter)
+	cursor, err := s.orgCollection.Find(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -97,13 +98,9 @@ func GetOrganizationsByMemberEmail(ctx context.Context, userEmail string) ([]mod
 	return orgs, nil
 }
 
-func UpdateOrganization(ctx context.Context, org *model.Organization) error {
-	// Check if organization exists
-	_, err := GetOrganizationByID(ctx, org.OrganizationId)
-	if err != nil {
-		return errors.New("Organization not found")
-	}
-
+// UpdateOrganization saves the name, description and discoverability of the organization. Members are
+// changed with the member updates below, so a member who joins meanwhile is never overwritten.
+func (s *Store) UpdateOrganization(ctx context.Context, org *model.Organization) error {
 	// Define filter to find organization by ID
 	filter := bson.M{"organizationid": org.OrganizationId}
 
@@ -112,36 +109,258 @@ func UpdateOrganization(ctx context.Context, org *model.Organization) error {
 		"$set": primitive.M{
 			"name":        org.Name,
 			"description": org.Description,
-			"organizationmembers": org.OrganizationMembers,
+			"discoverable": org.Discoverable,
 		},
 	}
 
 	// Perform update operation
-	_, err = orgCollection.UpdateOne(ctx, filter, update)
+	result, err := s.orgCollection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return errors.New("Organization not found")
+	}
 
 	return nil
 }
 
 // DeleteOrganization deletes an organization from the database by its ID.
-func DeleteOrganization(ctx context.Context, orgID string) error {
-	// Check if organization exists
-	_, err := GetOrganizationByID(ctx, orgID)
-	if err != nil {
-		return errors.New("Organization not found")
-	}
-
+func (s *Store) DeleteOrganization(ctx context.Context, orgID string) error {
 	// Define filter to find organization by ID
 	filter := bson.M{"organizationid": orgID}
 
 	// Delete the organization from the database
-	_, err = orgCollection.DeleteOne(ctx, filter)
+	result, err := s.orgCollection.DeleteOne(ctx, filter)
 	if err != nil {
 		return err
 	}
+	if result.DeletedCount == 0 {
+		return errors.New("Organization not found")
+	}
 
 	return nil
 }
 
+// CountOrganizations counts the organizations matching filter.
+func (s *Store) CountOrganizations(ctx context.Context, filter bson.M) (int64, error) {
+	return s.orgCollection.CountDocuments(ctx, filter)
+}
+
+// FindOrganizations retrieves a page of organizations matching filter, sorted by name, along with the total number of matches.
+func (s *Store) FindOrganizations(ctx context.Context, filter bson.M, skip, limit int64) ([]model.Organization, int64, error) {
+	total, err := s.orgCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.M{"name": 1}).SetSkip(skip).SetLimit(limit)
+	cursor, err := s.orgCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	orgs := []model.Organization{}
+	if err := cursor.All(ctx, &orgs); err != nil {
+		return nil, 0, err
+	}
+	return orgs, total, nil
+}
+
+// AddOrganizationMember appends member unless the organization already has a member with that email, in any
+// case. It reports whether the member was added. Checking and adding in one update keeps concurrent joins
+// from overwriting each other's members.
+func (s *Store) AddOrganizationMember(ctx context.Context, orgID string, member model.OrganizationMember) (bool, error) {
+	filter := bson.M{
+		"organizationid":      orgID,
+		"organizationmembers": bson.M{"$not": bson.M{"$elemMatch": bson.M{"useremail": emailPattern(member.UserEmail)}}},
+	}
+	result, err := s.orgCollection.UpdateOne(ctx, filter, bson.M{"$push": bson.M{"organizationmembers": member}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// RemoveAddedOrganizationMember undoes AddOrganizationMember, removing the user member with the given email
+// and access level. Founders and service accounts are never removed this way.
+func (s *Store) RemoveAddedOrganizationMember(ctx context.Context, orgID string, member model.OrganizationMember) error {
+	if member.AccessLevel == model.AccessLevelFounder {
+		return nil
+	}
+	pull := bson.M{
+		"useremail":   emailPattern(member.UserEmail),
+		"accesslevel": member.AccessLevel,
+		"type":        model.MemberTypeUser,
+	}
+	_, err := s.orgCollection.UpdateOne(ctx, bson.M{"organizationid": orgID}, bson.M{"$pull": bson.M{"organizationmembers": pull}})
+	return err
+}
+
+// SetOrganizationMemberAccessLevel changes the access level of the member with the given email, unless they
+// are a Founder or a service account. It reports whether a member was changed.
+func (s *Store) SetOrganizationMemberAccessLevel(ctx context.Context, orgID, email, accessLevel string) (bool, error) {
+	filter := bson.M{
+		"organizationid": orgID,
+		"organizationmembers": bson.M{"$elemMatch": bson.M{
+			"useremail":   emailPattern(email),
+			"accesslevel": bson.M{"$ne": model.AccessLevelFounder},
+			"type":        bson.M{"$ne": model.MemberTypeServiceAccount},
+		}},
+	}
+	update := bson.M{"$set": bson.M{"organizationmembers.$.accesslevel": accessLevel}}
+	result, err := s.orgCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// SetMemberAccessLevel changes the access level of the member with the given email, whatever their current
+// access level or type. It reports whether the organization has such a member.
+func (s *Store) SetMemberAccessLevel(ctx context.Context, orgID, email, accessLevel string) (bool, error) {
+	filter := bson.M{"organizationid": orgID, "organizationmembers.useremail": emailPattern(email)}
+	update := bson.M{"$set": bson.M{"organizationmembers.$[member].accesslevel": accessLevel}}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"member.useremail": emailPattern(email)}},
+	})
+	result, err := s.orgCollection.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// RemoveOrganizationMember removes the member with the given email, in any case. It reports whether a member
+// was removed.
+func (s *Store) RemoveOrganizationMember(ctx context.Context, orgID, email string) (bool, error) {
+	update := bson.M{"$pull": bson.M{"organizationmembers": bson.M{"useremail": emailPattern(email)}}}
+	result, err := s.orgCollection.UpdateOne(ctx, bson.M{"organizationid": orgID}, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// TransferOrganizationOwnership makes founder the only Founder of the organization, adding them if they
+// aren't a member yet. The other Founders become admins.
+func (s *Store) TransferOrganizationOwnership(ctx context.Context, orgID string, founder model.OrganizationMember) error {
+	founder.AccessLevel = model.AccessLevelFounder
+	if _, err := s.AddOrganizationMember(ctx, orgID, founder); err != nil {
+		return err
+	}
+	update := bson.M{"$set": bson.M{
+		"organizationmembers.$[founder].accesslevel":  model.AccessLevelFounder,
+		"organizationmembers.$[previous].accesslevel": model.AccessLevelAdmin,
+	}}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{
+			bson.M{"founder.useremail": emailPattern(founder.UserEmail)},
+			bson.M{
+				"previous.useremail":   bson.M{"$not": emailPattern(founder.UserEmail)},
+				"previous.accesslevel": model.AccessLevelFounder,
+			},
+		},
+	})
+	_, err := s.orgCollection.UpdateOne(ctx, bson.M{"organizationid": orgID}, update, opts)
+	return err
+}
+
+// emailPattern matches an email exactly but ignoring case, the way Organization.FindMember compares them.
+func emailPattern(email string) bson.M {
+	return bson.M{"$regex": "^" + regexp.QuoteMeta(email) + "$", "$options": "i"}
+}
+
+// RemoveMemberFromAllOrganizations removes the member with the given email, in any case, from every organization.
+func (s *Store) RemoveMemberFromAllOrganizations(ctx context.Context, email string) error {
+	filter := bson.M{"organizationmembers.useremail": emailPattern(email)}
+	update := bson.M{"$pull": bson.M{"organizationmembers": bson.M{"useremail": emailPattern(email)}}}
+	_, err := s.orgCollection.UpdateMany(ctx, filter, update)
+	return err
+}
+
+// RenameMemberEmail updates the member email, stored in any case, in every organization the member belongs to.
+func (s *Store) RenameMemberEmail(ctx context.Context, oldEmail, newEmail string) error {
+	filter := bson.M{"organizationmembers.useremail": emailPattern(oldEmail)}
+	update := bson.M{"$set": bson.M{"organizationmembers.$[member].useremail": newEmail}}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"member.useremail": emailPattern(oldEmail)}},
+	})
+	_, err := s.orgCollection.UpdateMany(ctx, filter, update, opts)
+	return err
+}
+
+// OrganizationWithCounts is an organization along with the number of records that belong to it.
+type OrganizationWithCounts struct {
+	model.Organization  `bson:",inline"`
+	DomainCount         int `bson:"domain_count"`
+	PendingJoinRequests int `bson:"pending_join_requests"`
+	ActiveInviteLinks   int `bson:"active_invite_links"`
+}
+
+// FindOrganizationsWithCounts retrieves a page of organizations matching filter, sorted by name, counting
+// their domains, pending join requests and active invite links in the same aggregation.
+func (s *Store) FindOrganizationsWithCounts(ctx context.Context, filter bson.M, skip, limit int64) ([]OrganizationWithCounts, int64, error) {
+	total, err := s.orgCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bson.D{{Key: "name", Value: 1}}}},
+		{{Key: "$skip", Value: skip}},
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+	pipeline = append(pipeline,
+		countLookup(s.organizationDomainCollection.Name(), "domains"),
+		countLookup(s.joinRequestCollection.Name(), "pending_requests",
+			bson.M{"$eq": bson.A{"$status", model.JoinRequestPending}}),
+		countLookup(s.inviteLinkCollection.Name(), "active_links",
+			bson.M{"$eq": bson.A{bson.M{"$type": "$revoked_at"}, "missing"}},
+			bson.M{"$gt": bson.A{"$expires_at", time.Now()}},
+			bson.M{"$or": bson.A{
+				bson.M{"$eq": bson.A{"$max_uses", 0}},
+				bson.M{"$lt": bson.A{"$uses", "$max_uses"}},
+			}},
+		),
+		bson.D{{Key: "$addFields", Value: bson.M{
+			"domain_count":          bson.M{"$size": "$domains"},
+			"pending_join_requests": bson.M{"$size": "$pending_requests"},
+			"active_invite_links":   bson.M{"$size": "$active_links"},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{"domains": 0, "pending_requests": 0, "active_links": 0}}},
+	)
+
+	cursor, err := s.orgCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	orgs := []OrganizationWithCounts{}
+	if err := cursor.All(ctx, &orgs); err != nil {
+		return nil, 0, err
+	}
+	return orgs, total, nil
+}
+
+// countLookup joins the ids of the organization's records in collection that match conditions into field.
+func countLookup(collection, field string, conditions ...bson.M) bson.D {
+	match := bson.A{bson.M{"$eq": bson.A{"$organization_id", "$$organization_id"}}}
+	for _, condition := range conditions {
+		match = append(match, condition)
+	}
+	return bson.D{{Key: "$lookup", Value: bson.M{
+		"from": collection,
+		"let":  bson.M{"organization_id": "$organizationid"},
+		"pipeline": bson.A{
+			bson.M{"$match": bson.M{"$expr": bson.M{"$and": match}}},
+			bson.M{"$project": bson.M{"_id": 1}},
+		},
+		"as": field,
+	}}}
+}
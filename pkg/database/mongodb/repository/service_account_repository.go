@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	model "organization_management/pkg/database/mongodb/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// InsertServiceAccount stores a new service account.
+func (s *Store) InsertServiceAccount(ctx context.Context, account model.ServiceAccount) error {
+	_, err := s.serviceAccountCollection.InsertOne(ctx, account)
+	return err
+}
+
+// GetServiceAccountByClientID retrieves a service account by client id, or nil if it doesn't exist.
+func (s *Store) GetServiceAccountByClientID(ctx context.Context, clientID string) (*model.ServiceAccount, error) {
+	var account model.ServiceAccount
+	err := s.serviceAccountCollection.FindOne(ctx, bson.M{"client_id": clientID}).Decode(&account)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetServiceAccountsByOrganizationID retrieves the service accounts of an organization.
+func (s *Store) GetServiceAccountsByOrganizationID(ctx context.Context, orgID string) ([]model.ServiceAccount, error) {
+	cursor, err := s.serviceAccountCollection.Find(ctx, bson.M{"organization_id": orgID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	accounts := []model.ServiceAccount{}
+	if err := cursor.All(ctx, &accounts); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// UpdateServiceAccountAccessLevel changes the access level of a service account.
+func (s *Store) UpdateServiceAccountAccessLevel(ctx context.Context, clientID, accessLevel string) error {
+	_, err := s.serviceAccountCollection.UpdateOne(ctx,
+		bson.M{"client_id": clientID},
+		bson.M{"$set": bson.M{"access_level": accessLevel}},
+	)
+	return err
+}
+
+// DisableServiceAccount disables a single service account.
+func (s *Store) DisableServiceAccount(ctx context.Context, clientID string) error {
+	_, err := s.serviceAccountCollection.UpdateOne(ctx,
+		bson.M{"client_id": clientID},
+		bson.M{"$set": bson.M{"disabled": true, "disabled_at": time.Now()}},
+	)
+	return err
+}
+
+// DisableServiceAccountsByOrganizationID disables every service account of an organization.
+func (s *Store) DisableServiceAccountsByOrganizationID(ctx context.Context, orgID string) error {
+	_, err := s.serviceAccountCollection.UpdateMany(ctx,
+		bson.M{"organization_id": orgID, "disabled": false},
+		bson.M{"$set": bson.M{"disabled": true, "disabled_at": time.Now()}},
+	)
+	return err
+}
+
+// DeleteServiceAccountsByOrganizationID removes every service account of an organization.
+func (s *Store) DeleteServiceAccountsByOrganizationID(ctx context.Context, orgID string) error {
+	_, err := s.serviceAccountCollection.DeleteMany(ctx, bson.M{"organization_id": orgID})
+	return err
+}
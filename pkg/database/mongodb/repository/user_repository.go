@@ -3,49 +3,176 @@ package repository
 import (
     "context"
     "errors"
-    
+
     model "organization_management/pkg/database/mongodb/models"
-	database "organization_management/pkg/database/mongodb"
 
     "go.mongodb.org/mongo-driver/bson/primitive"
     "go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var userCollection *mongo.Collection
-
-func init() {
-    userCollection = database.GetCollection(database.DB, "users")
-}
-
 // InsertUser inserts a new user into the database.
-func InsertUser(ctx context.Context, user model.User) (*mongo.InsertOneResult, error) {
+func (s *Store) InsertUser(ctx context.Context, user model.User) (*mongo.InsertOneResult, error) {
     newUser := model.User{
         Id:       primitive.NewObjectID(),
         Name:     user.Name,
         Email:    user.Email,
         Password: user.Password,
+        Identities: user.Identities,
+        ExternalId: user.ExternalId,
+        Status: user.Status,
+        PlatformRole: user.PlatformRole,
     }
 
-    return userCollection.InsertOne(ctx, newUser)
+    return s.userCollection.InsertOne(ctx, newUser)
+}
+
+// GetUserByEmail retrieves a user by email from the database, ignoring case. A user stored with exactly
+// that email is preferred over one that differs only in case.
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	user, err := s.findUser(ctx, bson.M{"email": email})
+	if user != nil || err != nil {
+		return user, err
+	}
+	return s.findUser(ctx, bson.M{"email": emailPattern(email)})
+}
+
+func (s *Store) findUser(ctx context.Context, filter bson.M) (*model.User, error) {
+	var user model.User
+	err := s.userCollection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserByFederatedIdentity retrieves the user linked to an account at an upstream identity provider.
+func (s *Store) GetUserByFederatedIdentity(ctx context.Context, provider, subject string) (*model.User, error) {
+	filter := bson.M{"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": subject}}}
+
+	var user model.User
+	err := s.userCollection.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
 }
 
-// GetUserByEmail retrieves a user by email from the database.
-func GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
-	// Define a filter to find the user by email
+// AddFederatedIdentity links an upstream identity to the user with the given email.
+func (s *Store) AddFederatedIdentity(ctx context.Context, email string, identity model.FederatedIdentity) error {
 	filter := bson.M{"email": email}
+	update := bson.M{"$addToSet": bson.M{"identities": identity}}
+	_, err := s.userCollection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// GetUserByID retrieves a user by the hex representation of its id, or nil if it doesn't exist.
+func (s *Store) GetUserByID(ctx context.Context, id string) (*model.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	var user model.User
+	err = s.userCollection.FindOne(ctx, bson.M{"id": objectID}).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// CountUsers counts the users matching filter.
+func (s *Store) CountUsers(ctx context.Context, filter bson.M) (int64, error) {
+	return s.userCollection.CountDocuments(ctx, filter)
+}
+
+// FindUsers retrieves a page of users matching filter, sorted by email, along with the total number of matches.
+func (s *Store) FindUsers(ctx context.Context, filter bson.M, skip, limit int64) ([]model.User, int64, error) {
+	total, err := s.userCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.M{"email": 1}).SetSkip(skip).SetLimit(limit)
+	cursor, err := s.userCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []model.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// UpdateUser saves the user's profile and state.
+func (s *Store) UpdateUser(ctx context.Context, user *model.User) error {
+	filter := bson.M{"id": user.Id}
+	update := bson.M{
+		"$set": bson.M{
+			"name":         user.Name,
+			"email":        user.Email,
+			"externalid":   user.ExternalId,
+			"status":       user.Status,
+			"platformrole": user.PlatformRole,
+		},
+	}
+	_, err := s.userCollection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// DeleteUser removes the user with the given id.
+func (s *Store) DeleteUser(ctx context.Context, id primitive.ObjectID) error {
+	_, err := s.userCollection.DeleteOne(ctx, bson.M{"id": id})
+	return err
+}
+
+// SetPasswordReset stores a pending password reset for the user, replacing any earlier one.
+func (s *Store) SetPasswordReset(ctx context.Context, id primitive.ObjectID, reset model.PasswordReset) error {
+	_, err := s.userCollection.UpdateOne(ctx, bson.M{"id": id}, bson.M{"$set": bson.M{"passwordreset": reset}})
+	return err
+}
 
-	// Query the database to find the user
+// GetUserByPasswordResetHash retrieves the user with a pending reset for the hashed token, or nil if there is none.
+func (s *Store) GetUserByPasswordResetHash(ctx context.Context, hashedToken string) (*model.User, error) {
 	var user model.User
-	err := userCollection.FindOne(ctx, filter).Decode(&user)
+	err := s.userCollection.FindOne(ctx, bson.M{"passwordreset.hashedtoken": hashedToken}).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			// If the document doesn't exist or the result is empty, return nil
 			return nil, nil
 		}
-		// Return other errors
 		return nil, err
 	}
+	return &user, nil
+}
+
+// UpdateUserPassword stores a new password hash and clears any pending password reset.
+func (s *Store) UpdateUserPassword(ctx context.Context, id primitive.ObjectID, hashedPassword string) error {
+	update := bson.M{
+		"$set":   bson.M{"password": hashedPassword},
+		"$unset": bson.M{"passwordreset": ""},
+	}
+	_, err := s.userCollection.UpdateOne(ctx, bson.M{"id": id}, update)
+	return err
+}
 
-	return &user, nil // Return the user if found
-}
\ No newline at end of file
+// ReplacePasswordHash swaps the stored hash for an upgraded hash of the same password. Nothing changes
+// if the password was changed in the meantime.
+func (s *Store) ReplacePasswordHash(ctx context.Context, id primitive.ObjectID, oldHash, newHash string) error {
+	_, err := s.userCollection.UpdateOne(ctx, bson.M{"id": id, "password": oldHash}, bson.M{"$set": bson.M{"password": newHash}})
+	return err
+}
@@ -1,16 +1,27 @@
 package redis
 
 import (
+    "context"
+    "fmt"
+
     "github.com/go-redis/redis/v8"
-    "os"
+    "organization_management/pkg/config"
+    "organization_management/pkg/metrics"
+    "organization_management/pkg/tracing"
 )
 
-var RedisClient *redis.Client
-
-func InitRedis() {
-    RedisClient = redis.NewClient(&redis.Options{
-        Addr:     os.Getenv("REDIS_ADDR"),   
-        Password: "",
-        DB:       0,                            // Default DB
+// InitRedis creates a client from cfg and pings it, so an unreachable server is reported at startup.
+func InitRedis(ctx context.Context, cfg config.RedisConfig) (*redis.Client, error) {
+    client := redis.NewClient(&redis.Options{
+        Addr:     cfg.Addr,
+        Password: cfg.Password,
+        DB:       cfg.DB,
     })
+    client.AddHook(metrics.RedisHook())
+    client.AddHook(tracing.RedisHook())
+    if err := client.Ping(ctx).Err(); err != nil {
+        client.Close()
+        return nil, fmt.Errorf("pinging Redis: %w", err)
+    }
+    return client, nil
 }
@@ -0,0 +1,55 @@
+package repository_token
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// AuthorizationCode is what an OIDC authorization code stands for until it is redeemed.
+type AuthorizationCode struct {
+	ClientId            string    `json:"client_id"`
+	RedirectURI         string    `json:"redirect_uri"`
+	UserId              string    `json:"user_id"`
+	Email               string    `json:"email"`
+	Scope               string    `json:"scope"`
+	Nonce               string    `json:"nonce,omitempty"`
+	CodeChallenge       string    `json:"code_challenge"`
+	CodeChallengeMethod string    `json:"code_challenge_method"`
+	AuthTime            time.Time `json:"auth_time"`
+}
+
+type AuthorizationCodeRepository struct {
+	RedisClient *redis.Client
+}
+
+func NewAuthorizationCodeRepository(redisClient *redis.Client) *AuthorizationCodeRepository {
+	return &AuthorizationCodeRepository{RedisClient: redisClient}
+}
+
+// SaveCode stores an authorization code for a short time.
+func (repo *AuthorizationCodeRepository) SaveCode(ctx context.Context, code string, data AuthorizationCode, lifespan time.Duration) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("oidc_code:%s", code)
+	return repo.RedisClient.Set(ctx, key, payload, lifespan).Err()
+}
+
+// ConsumeCode returns the data behind an authorization code and deletes it so it can only be used once.
+func (repo *AuthorizationCodeRepository) ConsumeCode(ctx context.Context, code string) (*AuthorizationCode, error) {
+	key := fmt.Sprintf("oidc_code:%s", code)
+	payload, err := repo.RedisClient.GetDel(ctx, key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var data AuthorizationCode
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
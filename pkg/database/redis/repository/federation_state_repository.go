@@ -0,0 +1,49 @@
+package repository_token
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// FederationState is remembered between redirecting a user to an upstream provider and its callback.
+type FederationState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+type FederationStateRepository struct {
+	RedisClient *redis.Client
+}
+
+func NewFederationStateRepository(redisClient *redis.Client) *FederationStateRepository {
+	return &FederationStateRepository{RedisClient: redisClient}
+}
+
+// SaveState stores the state of a pending federated sign-in.
+func (repo *FederationStateRepository) SaveState(ctx context.Context, state string, data FederationState, lifespan time.Duration) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("federation_state:%s", state)
+	return repo.RedisClient.Set(ctx, key, payload, lifespan).Err()
+}
+
+// ConsumeState returns and deletes the state of a pending federated sign-in.
+func (repo *FederationStateRepository) ConsumeState(ctx context.Context, state string) (*FederationState, error) {
+	key := fmt.Sprintf("federation_state:%s", state)
+	payload, err := repo.RedisClient.GetDel(ctx, key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var data FederationState
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
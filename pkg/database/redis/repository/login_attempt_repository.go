@@ -0,0 +1,62 @@
+package repository_token
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// LoginAttemptRepository keeps failed sign-in counters and temporary blocks in Redis.
+// Keys are scoped by the caller, e.g. "account:<email>" or "ip:<address>".
+type LoginAttemptRepository struct {
+	RedisClient *redis.Client
+}
+
+func NewLoginAttemptRepository(redisClient *redis.Client) *LoginAttemptRepository {
+	return &LoginAttemptRepository{RedisClient: redisClient}
+}
+
+// incrementFailuresScript counts a failure and starts the window when the counter has no expiry yet, which
+// is the first failure or a counter left without one by an earlier version. Running both in one script
+// means a crash in between can't leave a counter, and so a lockout, that never expires.
+var incrementFailuresScript = redis.NewScript(`
+local failures = redis.call('INCR', KEYS[1])
+if redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return failures
+`)
+
+// IncrementFailures records a failed attempt and returns the number of failures within the window.
+func (repo *LoginAttemptRepository) IncrementFailures(ctx context.Context, key string, window time.Duration) (int64, error) {
+	attemptsKey := fmt.Sprintf("login_attempts:%s", key)
+	return incrementFailuresScript.Run(ctx, repo.RedisClient, []string{attemptsKey}, window.Milliseconds()).Int64()
+}
+
+// Block prevents further attempts for the given duration.
+func (repo *LoginAttemptRepository) Block(ctx context.Context, key string, duration time.Duration) error {
+	blockKey := fmt.Sprintf("login_block:%s", key)
+	return repo.RedisClient.Set(ctx, blockKey, 1, duration).Err()
+}
+
+// BlockedFor returns how long attempts remain blocked, or zero if they are allowed.
+func (repo *LoginAttemptRepository) BlockedFor(ctx context.Context, key string) (time.Duration, error) {
+	blockKey := fmt.Sprintf("login_block:%s", key)
+	ttl, err := repo.RedisClient.PTTL(ctx, blockKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// Reset clears the failure counter and any block for the key.
+func (repo *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	attemptsKey := fmt.Sprintf("login_attempts:%s", key)
+	blockKey := fmt.Sprintf("login_block:%s", key)
+	return repo.RedisClient.Del(ctx, attemptsKey, blockKey).Err()
+}
@@ -0,0 +1,74 @@
+package repository_token
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// slidingWindowScript drops requests older than the window, admits the new one if there is room
+// and returns whether it was admitted, the number of requests in the window and the milliseconds
+// until the oldest request leaves the window.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+// RateLimitResult describes the state of a sliding window after a request was counted.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	Reset     time.Duration
+}
+
+type RateLimitRepository struct {
+	RedisClient *redis.Client
+}
+
+func NewRateLimitRepository(redisClient *redis.Client) *RateLimitRepository {
+	return &RateLimitRepository{RedisClient: redisClient}
+}
+
+// Allow counts a request against the sliding window identified by key.
+func (repo *RateLimitRepository) Allow(ctx context.Context, key string, limit int64, window time.Duration) (*RateLimitResult, error) {
+	now := time.Now().UnixMilli()
+	values, err := slidingWindowScript.Run(ctx, repo.RedisClient,
+		[]string{fmt.Sprintf("rate_limit:%s", key)},
+		now, window.Milliseconds(), limit, uuid.New().String(),
+	).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := limit - values[1]
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &RateLimitResult{
+		Allowed:   values[0] == 1,
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
@@ -2,7 +2,10 @@ package repository_token
 
 import (
     "context"
+    "errors"
     "fmt"
+    "strconv"
+    "time"
 
     "github.com/go-redis/redis/v8"
 )
@@ -15,8 +18,7 @@ func NewTokenRepository(redisClient *redis.Client) *TokenRepository {
     return &TokenRepository{RedisClient: redisClient}
 }
 
-func (repo *TokenRepository) SaveRefreshToken(userID, refreshToken string) error {
-    ctx := context.Background()
+func (repo *TokenRepository) SaveRefreshToken(ctx context.Context, userID, refreshToken string) error {
     key := fmt.Sprintf("refresh_token:%s", userID)
     err := repo.RedisClient.Set(ctx, key, refreshToken, 0).Err()
     if err != nil {
@@ -25,13 +27,20 @@ func (repo *TokenRepository) SaveRefreshToken(userID, refreshToken string) error
     return nil
 }
 
-func (repo *TokenRepository) RevokeRefreshToken(refreshToken string) error {
-    ctx := context.Background()
-    // Get the user ID associated with the refresh token from Redis
-    userID, err := repo.RedisClient.Get(ctx, refreshToken).Result()
+func (repo *TokenRepository) GetRefreshToken(ctx context.Context, userID string) (string, error) {
+    key := fmt.Sprintf("refresh_token:%s", userID)
+    return repo.RedisClient.Get(ctx, key).Result()
+}
+
+func (repo *TokenRepository) RevokeRefreshToken(ctx context.Context, userID, refreshToken string) error {
+    // Only revoke the refresh token if it is the one currently stored for the user
+    storedToken, err := repo.GetRefreshToken(ctx, userID)
     if err != nil {
         return err
     }
+    if storedToken != refreshToken {
+        return errors.New("refresh token does not match the active token")
+    }
     // Construct the key for the refresh token
     key := fmt.Sprintf("refresh_token:%s", userID)
     // Delete the refresh token from Redis
@@ -42,8 +51,7 @@ func (repo *TokenRepository) RevokeRefreshToken(refreshToken string) error {
     return nil
 }
 
-func (repo *TokenRepository) RevokeRefreshTokenWithId(userID string) error {
-    ctx := context.Background()
+func (repo *TokenRepository) RevokeRefreshTokenWithId(ctx context.Context, userID string) error {
     key := fmt.Sprintf("refresh_token:%s", userID)
     err := repo.RedisClient.Del(ctx, key).Err()
     if err != nil {
@@ -51,3 +59,55 @@ func (repo *TokenRepository) RevokeRefreshTokenWithId(userID string) error {
     }
     return nil
 }
+
+// RevokeAllTokens revokes the user's refresh token and every access token issued until now.
+// The cutoff is kept for as long as any token issued before it could still be valid.
+func (repo *TokenRepository) RevokeAllTokens(ctx context.Context, userID string, maxTokenLifespan time.Duration) error {
+    if err := repo.RevokeRefreshTokenWithId(ctx, userID); err != nil {
+        return err
+    }
+    key := fmt.Sprintf("tokens_revoked_before_us:%s", userID)
+    return repo.RedisClient.Set(ctx, key, time.Now().UnixMicro(), maxTokenLifespan).Err()
+}
+
+// IsTokenRevoked reports whether a token issued to the user at issuedAt was revoked by RevokeAllTokens.
+// The cutoff has microsecond precision, so a token issued before the revocation is rejected even in the
+// same second, while one issued by signing in again right after stays valid. Cutoffs stored in seconds
+// by earlier versions are still honored until they expire.
+func (repo *TokenRepository) IsTokenRevoked(ctx context.Context, userID string, issuedAt time.Time) (bool, error) {
+    values, err := repo.RedisClient.MGet(ctx,
+        fmt.Sprintf("tokens_revoked_before_us:%s", userID),
+        fmt.Sprintf("tokens_revoked_before:%s", userID),
+    ).Result()
+    if err != nil {
+        return false, err
+    }
+    if value, ok := values[0].(string); ok {
+        revokedBefore, err := strconv.ParseInt(value, 10, 64)
+        if err != nil {
+            return false, err
+        }
+        if issuedAt.UnixMicro() <= revokedBefore {
+            return true, nil
+        }
+    }
+    if value, ok := values[1].(string); ok {
+        revokedBefore, err := strconv.ParseInt(value, 10, 64)
+        if err != nil {
+            return false, err
+        }
+        if issuedAt.Unix() < revokedBefore {
+            return true, nil
+        }
+    }
+    return false, nil
+}
+
+// FindRefreshToken returns the user's stored refresh token, or an empty string if they have none.
+func (repo *TokenRepository) FindRefreshToken(ctx context.Context, userID string) (string, error) {
+    token, err := repo.GetRefreshToken(ctx, userID)
+    if err == redis.Nil {
+        return "", nil
+    }
+    return token, err
+}
@@ -0,0 +1,210 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"organization_management/pkg/database/mongodb/command"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// Outcomes of authentication events.
+const (
+	ResultSucceeded = "succeeded"
+	ResultFailed    = "failed"
+)
+
+// Ways a user can sign in.
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodOIDCForm  = "oidc_form"
+	LoginMethodFederated = "federated"
+)
+
+// Kinds of invitations.
+const (
+	InviteKindDirect = "direct"
+	InviteKindLink   = "link"
+)
+
+// operationBuckets suit database calls, which mostly finish in a few milliseconds.
+var operationBuckets = prometheus.ExponentialBuckets(0.0005, 2, 14)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route template and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	mongoDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mongodb_operation_duration_seconds",
+		Help:    "MongoDB command latency by collection, command and outcome.",
+		Buckets: operationBuckets,
+	}, []string{"collection", "command", "outcome"})
+	redisDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "redis_operation_duration_seconds",
+		Help:    "Redis command latency by command and outcome.",
+		Buckets: operationBuckets,
+	}, []string{"command", "outcome"})
+
+	signups = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "auth_signups_total",
+		Help: "Accounts created through sign-up.",
+	})
+	logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_logins_total",
+		Help: "Sign-in attempts by method and result. Failures are rejected credentials, lockouts and inactive accounts.",
+	}, []string{"method", "result"})
+	tokenRefreshes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_token_refreshes_total",
+		Help: "Refresh token exchanges by result.",
+	}, []string{"result"})
+	organizationsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "organizations_created_total",
+		Help: "Organizations created.",
+	})
+	invitesSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "organization_invites_sent_total",
+		Help: "Invitations sent by kind: direct member invites and invite links.",
+	}, []string{"kind"})
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() gin.HandlerFunc {
+	handler := promhttp.Handler()
+	return func(c *gin.Context) {
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// Middleware records the latency and status of every request under its route template,
+// so /api/organization/:organization_id is one series rather than one per id.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(started).Seconds())
+	}
+}
+
+// MongoMonitor times every command the driver sends, which covers all repository calls.
+func MongoMonitor() *event.CommandMonitor {
+	var collections sync.Map
+	finished := func(evt event.CommandFinishedEvent, outcome string) {
+		collection, _ := collections.LoadAndDelete(command.FinishedKey(evt))
+		name, _ := collection.(string)
+		mongoDuration.WithLabelValues(name, evt.CommandName, outcome).Observe(evt.Duration.Seconds())
+	}
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+			collection, _ := evt.Command.Lookup(evt.CommandName).StringValueOK()
+			collections.Store(command.StartedKey(evt), collection)
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			finished(evt.CommandFinishedEvent, "ok")
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			finished(evt.CommandFinishedEvent, "error")
+		},
+	}
+}
+
+type redisStartKey struct{}
+
+// redisHook times every command sent through the shared Redis client.
+type redisHook struct{}
+
+// RedisHook returns a go-redis hook that records command latencies.
+func RedisHook() redis.Hook {
+	return redisHook{}
+}
+
+func (redisHook) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (redisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	observeRedis(ctx, cmd.Name(), cmd.Err())
+	return nil
+}
+
+func (redisHook) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (redisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && !errors.Is(cmdErr, redis.Nil) {
+			err = cmdErr
+		}
+	}
+	observeRedis(ctx, "pipeline", err)
+	return nil
+}
+
+func observeRedis(ctx context.Context, command string, err error) {
+	started, ok := ctx.Value(redisStartKey{}).(time.Time)
+	if !ok {
+		return
+	}
+	outcome := "ok"
+	// a missing key is an answer, not a failure
+	if err != nil && !errors.Is(err, redis.Nil) {
+		outcome = "error"
+	}
+	redisDuration.WithLabelValues(command, outcome).Observe(time.Since(started).Seconds())
+}
+
+// RecordSignup counts a new account.
+func RecordSignup() {
+	signups.Inc()
+}
+
+// RecordLogin counts a sign-in attempt with one of the LoginMethod values.
+func RecordLogin(method string, succeeded bool) {
+	logins.WithLabelValues(method, result(succeeded)).Inc()
+}
+
+// RecordTokenRefresh counts a refresh token exchange.
+func RecordTokenRefresh(succeeded bool) {
+	tokenRefreshes.WithLabelValues(result(succeeded)).Inc()
+}
+
+// RecordOrganizationCreated counts a new organization.
+func RecordOrganizationCreated() {
+	organizationsCreated.Inc()
+}
+
+// RecordInviteSent counts an invitation of one of the InviteKind values.
+func RecordInviteSent(kind string) {
+	invitesSent.WithLabelValues(kind).Inc()
+}
+
+func result(succeeded bool) string {
+	if succeeded {
+		return ResultSucceeded
+	}
+	return ResultFailed
+}
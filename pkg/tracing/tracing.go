@@ -0,0 +1,165 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"organization_management/pkg/config"
+	"organization_management/pkg/database/mongodb/command"
+
+	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "organization_management"
+
+// Setup installs the W3C trace context propagator and, unless the exporter is "none", a tracer provider
+// that batches spans to the configured exporter. The returned function flushes and stops the provider.
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if cfg.Exporter == config.TracingExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("building tracing resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case config.TracingExporterOTLP:
+		var options []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		}
+		return otlptracehttp.New(ctx, options...)
+	case config.TracingExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+}
+
+// Tracer returns the tracer for spans the application starts itself.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// MongoMonitor starts a client span for every command the driver sends, as a child of the
+// context the repository passed in. Command bodies are not recorded since they hold user data.
+func MongoMonitor() *event.CommandMonitor {
+	var spans sync.Map
+	finish := func(evt event.CommandFinishedEvent, err error) {
+		value, ok := spans.LoadAndDelete(command.FinishedKey(evt))
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			collection, _ := evt.Command.Lookup(evt.CommandName).StringValueOK()
+			name := evt.CommandName
+			if collection != "" {
+				name = collection + "." + evt.CommandName
+			}
+			_, span := Tracer().Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemMongoDB,
+					semconv.DBName(evt.DatabaseName),
+					semconv.DBOperation(evt.CommandName),
+					semconv.DBMongoDBCollection(collection),
+				),
+			)
+			spans.Store(command.StartedKey(evt), span)
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			finish(evt.CommandFinishedEvent, nil)
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			finish(evt.CommandFinishedEvent, errors.New(evt.Failure))
+		},
+	}
+}
+
+// redisHook starts a client span for every command sent through the shared Redis client.
+type redisHook struct{}
+
+// RedisHook returns a go-redis hook that traces commands and pipelines.
+func RedisHook() redis.Hook {
+	return redisHook{}
+}
+
+func (redisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	ctx, _ = startRedisSpan(ctx, "redis."+cmd.Name(), semconv.DBOperation(cmd.Name()))
+	return ctx, nil
+}
+
+func (redisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	endRedisSpan(ctx, cmd.Err())
+	return nil
+}
+
+func (redisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	ctx, _ = startRedisSpan(ctx, "redis.pipeline", attribute.Int("db.redis.pipeline_length", len(cmds)))
+	return ctx, nil
+}
+
+func (redisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && !errors.Is(cmdErr, redis.Nil) {
+			err = cmdErr
+		}
+	}
+	endRedisSpan(ctx, err)
+	return nil
+}
+
+func startRedisSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attributes, semconv.DBSystemRedis)...),
+	)
+}
+
+func endRedisSpan(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	// a missing key is an answer, not a failure
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// AdminSettings name the platform administrators and how long their impersonation tokens last.
+type AdminSettings struct {
+	Emails                []string
+	ImpersonationLifespan time.Duration
+}
+
+// NewAdminSettings checks settings and normalizes the administrator emails for comparison.
+func NewAdminSettings(settings AdminSettings) (AdminSettings, error) {
+	if settings.ImpersonationLifespan <= 0 {
+		return AdminSettings{}, errors.New("Impersonation token lifespan must be positive")
+	}
+	emails := make([]string, 0, len(settings.Emails))
+	for _, email := range settings.Emails {
+		if email = strings.TrimSpace(email); email != "" {
+			emails = append(emails, strings.ToLower(email))
+		}
+	}
+	return AdminSettings{Emails: emails, ImpersonationLifespan: settings.ImpersonationLifespan}, nil
+}
+
+// IsAdminEmail reports whether the email belongs to a platform administrator.
+func (s AdminSettings) IsAdminEmail(email string) bool {
+	for _, admin := range s.Emails {
+		if admin == strings.ToLower(email) {
+			return true
+		}
+	}
+	return false
+}
+
+// PasswordResetTokenPrefix starts every password reset token so it is recognizable when pasted around.
+const PasswordResetTokenPrefix = "pwr_"
+
+// PasswordResetLifespan is how long a password reset token handed out by an administrator stays valid.
+const PasswordResetLifespan = 72 * time.Hour
+
+// GeneratePasswordResetToken returns a new password reset token and its hash. Only the hash is stored.
+func GeneratePasswordResetToken() (token, hashedToken string, err error) {
+	secret, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	token = PasswordResetTokenPrefix + secret
+	return token, HashPasswordResetToken(token), nil
+}
+
+// HashPasswordResetToken hashes a password reset token for storage and lookup.
+func HashPasswordResetToken(token string) string {
+	return hashSecret(token)
+}
@@ -0,0 +1,117 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DomainChallengePrefix is the subdomain holding the TXT record that proves control of a domain.
+	DomainChallengePrefix = "_orgmanagement-challenge"
+	// DomainChallengeValuePrefix starts the TXT record value, followed by the verification token.
+	DomainChallengeValuePrefix = "orgmanagement-verification="
+)
+
+var ErrInvalidDomain = errors.New("Invalid domain")
+
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// DomainVerifier checks that whoever claims a domain controls it.
+type DomainVerifier interface {
+	Verify(ctx context.Context, domain, token string) (bool, error)
+}
+
+// DNSDomainVerifier looks for the challenge token in the TXT records of the challenge subdomain.
+type DNSDomainVerifier struct {
+	Resolver *net.Resolver
+}
+
+// Verify reports whether a TXT record at _orgmanagement-challenge.<domain> carries the token.
+func (v DNSDomainVerifier) Verify(ctx context.Context, domain, token string) (bool, error) {
+	resolver := v.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	records, err := resolver.LookupTXT(ctx, DomainChallengeRecord(domain))
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, record := range records {
+		if strings.TrimSpace(record) == DomainChallengeValue(token) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// StaticDomainVerifier accepts or refuses every domain. It stands in for DNS in local development and tests.
+type StaticDomainVerifier struct {
+	Verified bool
+}
+
+// Verify returns the configured answer.
+func (v StaticDomainVerifier) Verify(ctx context.Context, domain, token string) (bool, error) {
+	return v.Verified, nil
+}
+
+// Domain verifiers NewDomainVerifier can build.
+const (
+	DomainVerifierDNS  = "dns"
+	DomainVerifierStub = "stub"
+)
+
+// NewDomainVerifier returns the named verifier: "dns" or "stub", which accepts every domain and must
+// never be used in production.
+func NewDomainVerifier(name string) (DomainVerifier, error) {
+	switch name {
+	case DomainVerifierDNS:
+		return DNSDomainVerifier{}, nil
+	case DomainVerifierStub:
+		log.Println("The domain verifier is stub, every domain claim will be accepted")
+		return StaticDomainVerifier{Verified: true}, nil
+	default:
+		return nil, fmt.Errorf("Unknown domain verifier %q", name)
+	}
+}
+
+// DomainChallengeRecord returns the DNS name that must hold the challenge TXT record.
+func DomainChallengeRecord(domain string) string {
+	return DomainChallengePrefix + "." + domain
+}
+
+// DomainChallengeValue returns the TXT record value expected for the token.
+func DomainChallengeValue(token string) string {
+	return DomainChallengeValuePrefix + token
+}
+
+// NormalizeDomain lowercases the domain and checks that it is a plain host name.
+func NormalizeDomain(domain string) (string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if len(domain) > 253 || !domainPattern.MatchString(domain) {
+		return "", ErrInvalidDomain
+	}
+	return domain, nil
+}
+
+// EmailDomain returns the lowercased domain part of an email address.
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+// GenerateDomainVerificationToken returns a random token to publish in the challenge TXT record.
+func GenerateDomainVerificationToken() (string, error) {
+	return randomString(24)
+}
@@ -0,0 +1,154 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var ErrUnknownProvider = errors.New("Unknown identity provider")
+
+// FederatedProviderConfig describes an upstream OpenID Connect identity provider.
+type FederatedProviderConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// FederatedIdentity is what an upstream provider asserts about the user who signed in.
+type FederatedIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// FederatedProvider runs the authorization code flow against one upstream provider.
+type FederatedProvider struct {
+	config   FederatedProviderConfig
+	oauth2   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// FederatedProviders are the upstream providers users can sign in with, keyed by name. Each one is
+// discovered on first use and kept for later sign-ins.
+type FederatedProviders struct {
+	configs    map[string]FederatedProviderConfig
+	mu         sync.Mutex
+	discovered map[string]*FederatedProvider
+}
+
+// NewFederatedProviders returns the providers for configs, none of which is discovered yet.
+func NewFederatedProviders(configs map[string]FederatedProviderConfig) *FederatedProviders {
+	return &FederatedProviders{configs: configs, discovered: map[string]*FederatedProvider{}}
+}
+
+// Get returns the configured provider, fetching its discovery document on first use.
+// Discovery runs without holding the lock, so a slow provider doesn't hold up sign-ins with the others.
+func (p *FederatedProviders) Get(ctx context.Context, name string) (*FederatedProvider, error) {
+	config, configured := p.configs[name]
+	if !configured {
+		return nil, ErrUnknownProvider
+	}
+	p.mu.Lock()
+	provider, ok := p.discovered[name]
+	p.mu.Unlock()
+	if ok {
+		return provider, nil
+	}
+	provider, err := NewFederatedProvider(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep whichever provider was discovered first when sign-ins raced
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if existing, ok := p.discovered[name]; ok {
+		return existing, nil
+	}
+	p.discovered[name] = provider
+	return provider, nil
+}
+
+// NewFederatedProvider discovers the provider's endpoints and keys from its issuer URL.
+func NewFederatedProvider(ctx context.Context, config FederatedProviderConfig) (*FederatedProvider, error) {
+	discovered, err := oidc.NewProvider(ctx, config.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("discovering %s: %w", config.Name, err)
+	}
+	return &FederatedProvider{
+		config: config,
+		oauth2: oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Endpoint:     discovered.Endpoint(),
+			Scopes:       config.Scopes,
+		},
+		verifier: discovered.Verifier(&oidc.Config{ClientID: config.ClientID}),
+	}, nil
+}
+
+// AuthCodeURL returns the provider URL the user is sent to, bound to state, nonce and a PKCE verifier.
+func (p *FederatedProvider) AuthCodeURL(state, nonce, codeVerifier string) string {
+	return p.oauth2.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(codeVerifier))
+}
+
+// Exchange redeems the authorization code and verifies the returned ID token and its nonce.
+func (p *FederatedProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*FederatedIdentity, error) {
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, err
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("Provider did not return an ID token")
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("ID token nonce does not match")
+	}
+
+	var claims struct {
+		Email         string      `json:"email"`
+		EmailVerified interface{} `json:"email_verified"`
+		Name          string      `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	// Some providers send email_verified as a string
+	verified := claims.EmailVerified == true || claims.EmailVerified == "true"
+	return &FederatedIdentity{
+		Provider:      p.config.Name,
+		Subject:       idToken.Subject,
+		Email:         strings.ToLower(claims.Email),
+		EmailVerified: verified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// GenerateFederationState returns a random state, nonce and PKCE verifier for a new sign-in.
+func GenerateFederationState() (state, nonce, codeVerifier string, err error) {
+	if state, err = randomString(24); err != nil {
+		return "", "", "", err
+	}
+	if nonce, err = randomString(24); err != nil {
+		return "", "", "", err
+	}
+	return state, nonce, oauth2.GenerateVerifier(), nil
+}
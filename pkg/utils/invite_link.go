@@ -0,0 +1,19 @@
+package util
+
+// InviteTokenPrefix starts every invite link token so it is recognizable when pasted around.
+const InviteTokenPrefix = "inv_"
+
+// GenerateInviteToken returns a new invite link token and its hash. Only the hash is stored.
+func GenerateInviteToken() (token, hashedToken string, err error) {
+	secret, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	token = InviteTokenPrefix + secret
+	return token, HashInviteToken(token), nil
+}
+
+// HashInviteToken hashes an invite token for storage and lookup.
+func HashInviteToken(token string) string {
+	return hashSecret(token)
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"errors"
+	"time"
+)
+
+// LoginPolicy controls how failed sign-in attempts are throttled.
+type LoginPolicy struct {
+	MaxAccountAttempts int64
+	MaxIPAttempts      int64
+	Window             time.Duration
+	Lockout            time.Duration
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+}
+
+// LoginPolicySettings are the values a LoginPolicy is built from.
+type LoginPolicySettings struct {
+	MaxAccountAttempts int64
+	MaxIPAttempts      int64
+	Window             time.Duration
+	Lockout            time.Duration
+}
+
+// NewLoginPolicy builds a policy from settings. The delays between attempts are fixed.
+func NewLoginPolicy(settings LoginPolicySettings) (LoginPolicy, error) {
+	if settings.MaxAccountAttempts <= 0 || settings.MaxIPAttempts <= 0 {
+		return LoginPolicy{}, errors.New("Sign-in attempt limits must be positive")
+	}
+	if settings.Window <= 0 || settings.Lockout <= 0 {
+		return LoginPolicy{}, errors.New("Sign-in attempt window and lockout must be positive")
+	}
+	return LoginPolicy{
+		MaxAccountAttempts: settings.MaxAccountAttempts,
+		MaxIPAttempts:      settings.MaxIPAttempts,
+		Window:             settings.Window,
+		Lockout:            settings.Lockout,
+		BaseDelay:          time.Second,
+		MaxDelay:           30 * time.Second,
+	}, nil
+}
+
+// DelayAfter returns how long the next attempt is held back after the given number of failures.
+// The delay doubles with every failure, starting after the first one.
+func (p LoginPolicy) DelayAfter(failures int64) time.Duration {
+	if failures <= 1 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := int64(2); i < failures; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return delay
+}
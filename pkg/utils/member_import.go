@@ -0,0 +1,173 @@
+package util
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats accepted by the member import.
+const (
+	MemberImportCSV   = "csv"
+	MemberImportJSONL = "jsonl"
+
+	// MaxMemberImportRows bounds how many members one import can touch.
+	MaxMemberImportRows = 5000
+)
+
+var (
+	ErrUnsupportedImportFormat = errors.New("Unsupported import format, use csv or jsonl")
+	ErrTooManyImportRows       = fmt.Errorf("An import can contain at most %d rows", MaxMemberImportRows)
+)
+
+// MemberImportRow is one member read from an import file. Line is the 1-based line number in the file.
+type MemberImportRow struct {
+	Line        int    `json:"line"`
+	Email       string `json:"email"`
+	AccessLevel string `json:"access_level"`
+}
+
+// MemberImportError describes why a row of an import file was skipped.
+type MemberImportError struct {
+	Line  int    `json:"line"`
+	Email string `json:"email,omitempty"`
+	Error string `json:"error"`
+}
+
+// MemberImportFormat picks the format from an explicit format name or, failing that, the content type.
+func MemberImportFormat(format, contentType string) (string, error) {
+	switch strings.ToLower(format) {
+	case MemberImportCSV, MemberImportJSONL:
+		return strings.ToLower(format), nil
+	case "":
+	default:
+		return "", ErrUnsupportedImportFormat
+	}
+
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return MemberImportCSV, nil
+	case "application/jsonl", "application/x-ndjson", "application/x-jsonlines", "application/jsonlines":
+		return MemberImportJSONL, nil
+	}
+	return "", ErrUnsupportedImportFormat
+}
+
+// ParseMemberImport reads member rows in the given format. Rows that can't be read are returned as errors
+// so the rest of the file can still be imported; a file that can't be read at all returns an error.
+func ParseMemberImport(r io.Reader, format string) ([]MemberImportRow, []MemberImportError, error) {
+	switch format {
+	case MemberImportCSV:
+		return parseMemberImportCSV(r)
+	case MemberImportJSONL:
+		return parseMemberImportJSONL(r)
+	}
+	return nil, nil, ErrUnsupportedImportFormat
+}
+
+// parseMemberImportCSV expects a header row with an email column and optionally an access_level column.
+func parseMemberImportCSV(r io.Reader) ([]MemberImportRow, []MemberImportError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	emailColumn, accessLevelColumn := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "email":
+			emailColumn = i
+		case "access_level":
+			accessLevelColumn = i
+		}
+	}
+	if emailColumn < 0 {
+		return nil, nil, errors.New("CSV header must contain an email column")
+	}
+
+	var rows []MemberImportRow
+	var rowErrors []MemberImportError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, MemberImportError{Line: parseErr.StartLine, Error: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, err
+		}
+		if len(rows) >= MaxMemberImportRows {
+			return nil, nil, ErrTooManyImportRows
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := MemberImportRow{Line: line}
+		if emailColumn < len(record) {
+			row.Email = strings.TrimSpace(record[emailColumn])
+		}
+		if accessLevelColumn >= 0 && accessLevelColumn < len(record) {
+			row.AccessLevel = strings.TrimSpace(record[accessLevelColumn])
+		}
+		if row.Email == "" && row.AccessLevel == "" {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, rowErrors, nil
+}
+
+// parseMemberImportJSONL expects one JSON object per line with an email and optionally an access_level.
+func parseMemberImportJSONL(r io.Reader) ([]MemberImportRow, []MemberImportError, error) {
+	scanner := bufio.NewScanner(r)
+	var rows []MemberImportRow
+	var rowErrors []MemberImportError
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if len(rows) >= MaxMemberImportRows {
+			return nil, nil, ErrTooManyImportRows
+		}
+
+		var row MemberImportRow
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			rowErrors = append(rowErrors, MemberImportError{Line: line, Error: "Invalid JSON: " + err.Error()})
+			continue
+		}
+		row.Line = line
+		row.Email = strings.TrimSpace(row.Email)
+		row.AccessLevel = strings.TrimSpace(row.AccessLevel)
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return rows, rowErrors, nil
+}
+
+// CSVSafe neutralizes values that spreadsheet applications would otherwise run as formulas.
+func CSVSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,142 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCSigner signs ID tokens with an RSA key that relying parties can verify through the JWKS endpoint.
+type OIDCSigner struct {
+	key    *rsa.PrivateKey
+	keyID  string
+	issuer string
+}
+
+// OIDCSettings are the values an OIDCSigner is loaded from.
+type OIDCSettings struct {
+	Issuer         string
+	PrivateKeyPath string
+}
+
+// LoadOIDCSigner returns a signer for settings.Issuer, loading its key from settings.PrivateKeyPath.
+// Without a path an ephemeral key is generated, which invalidates issued ID tokens on every restart
+// and is only meant for local use.
+func LoadOIDCSigner(settings OIDCSettings) (*OIDCSigner, error) {
+	var key *rsa.PrivateKey
+	var err error
+	if settings.PrivateKeyPath != "" {
+		key, err = loadRSAPrivateKey(settings.PrivateKeyPath)
+	} else {
+		log.Println("No OIDC private key is configured, generating an ephemeral OIDC signing key")
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading the OIDC signing key: %w", err)
+	}
+	return NewOIDCSigner(key, settings.Issuer), nil
+}
+
+// NewOIDCSigner wraps an RSA key, deriving its key id from the public key. ID tokens it signs name issuer as their iss.
+func NewOIDCSigner(key *rsa.PrivateKey, issuer string) *OIDCSigner {
+	sum := sha256.Sum256(x509.MarshalPKCS1PublicKey(&key.PublicKey))
+	return &OIDCSigner{
+		key:    key,
+		keyID:  base64.RawURLEncoding.EncodeToString(sum[:8]),
+		issuer: strings.TrimSuffix(issuer, "/"),
+	}
+}
+
+// Issuer returns the issuer identifier advertised in discovery and used as the iss of ID tokens.
+func (s *OIDCSigner) Issuer() string {
+	return s.issuer
+}
+
+// SignIDToken signs an ID token for the client with the given claims.
+func (s *OIDCSigner) SignIDToken(subject, clientID string, lifespan time.Duration, claims jwt.MapClaims) (string, error) {
+	now := time.Now()
+	claims["iss"] = s.issuer
+	claims["sub"] = subject
+	claims["aud"] = clientID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(lifespan).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.keyID
+	return token.SignedString(s.key)
+}
+
+// JWKS returns the public signing key as a JSON Web Key Set.
+func (s *OIDCSigner) JWKS() map[string]interface{} {
+	return map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": jwt.SigningMethodRS256.Alg(),
+			"kid": s.keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(s.key.PublicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.PublicKey.E)).Bytes()),
+		}},
+	}
+}
+
+// VerifyPKCE checks a code_verifier against the S256 code_challenge sent to the authorize endpoint.
+func VerifyPKCE(codeVerifier, codeChallenge, method string) bool {
+	if method != "S256" || codeVerifier == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(codeVerifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(codeChallenge)) == 1
+}
+
+// GenerateAuthorizationCode returns a random, URL safe authorization code.
+func GenerateAuthorizationCode() (string, error) {
+	return randomString(32)
+}
+
+// GenerateCSRFToken returns a random value that ties a form submission to the browser the form was shown in.
+func GenerateCSRFToken() (string, error) {
+	return randomString(32)
+}
+
+// CSRFTokenMatches compares the value posted with a form to the one kept in the browser's cookie.
+func CSRFTokenMatches(posted, cookie string) bool {
+	return posted != "" && subtle.ConstantTimeCompare([]byte(posted), []byte(cookie)) == 1
+}
+
+func loadRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("OIDC private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("OIDC private key is not an RSA key")
+	}
+	return key, nil
+}
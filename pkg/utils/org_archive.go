@@ -0,0 +1,131 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// OrganizationArchiveVersion is the version of the archive format written by exports.
+// Imports refuse archives of any other version.
+const OrganizationArchiveVersion = 1
+
+// Ways an import resolves an organization whose name already exists in the target deployment.
+const (
+	ArchiveConflictFail   = "fail"
+	ArchiveConflictSkip   = "skip"
+	ArchiveConflictRename = "rename"
+)
+
+var (
+	ErrUnsupportedArchiveVersion = errors.New("Unsupported organization archive version")
+	ErrInvalidArchiveConflict    = errors.New("on_conflict must be fail, skip or rename")
+)
+
+// OrganizationArchive is a self-contained copy of an organization that can be restored in another deployment.
+// Its fields are decoupled from the stored models so the format only changes with its version.
+type OrganizationArchive struct {
+	Version         int                      `json:"version"`
+	ExportedAt      time.Time                `json:"exported_at"`
+	Organization    ArchivedOrganization     `json:"organization"`
+	Domains         []ArchivedDomain         `json:"domains"`
+	InviteLinks     []ArchivedInviteLink     `json:"invite_links"`
+	JoinRequests    []ArchivedJoinRequest    `json:"join_requests"`
+	ServiceAccounts []ArchivedServiceAccount `json:"service_accounts"`
+	AuditEvents     []ArchivedAuditEvent     `json:"audit_events"`
+}
+
+type ArchivedOrganization struct {
+	OrganizationId string           `json:"organization_id"`
+	Name           string           `json:"name"`
+	Description    string           `json:"description"`
+	Discoverable   bool             `json:"discoverable"`
+	Members        []ArchivedMember `json:"members"`
+}
+
+type ArchivedMember struct {
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	AccessLevel string `json:"access_level"`
+	Type        string `json:"type,omitempty"`
+}
+
+type ArchivedDomain struct {
+	Domain             string     `json:"domain"`
+	VerificationToken  string     `json:"verification_token"`
+	Verified           bool       `json:"verified"`
+	AutoJoin           bool       `json:"auto_join"`
+	DefaultAccessLevel string     `json:"default_access_level"`
+	CreatedAt          time.Time  `json:"created_at"`
+	VerifiedAt         *time.Time `json:"verified_at,omitempty"`
+}
+
+// ArchivedInviteLink keeps the token hash so links handed out before a migration keep working afterwards.
+type ArchivedInviteLink struct {
+	LinkId      string     `json:"link_id"`
+	HashedToken string     `json:"hashed_token"`
+	AccessLevel string     `json:"access_level"`
+	MaxUses     int        `json:"max_uses"`
+	Uses        int        `json:"uses"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ArchivedJoinRequest identifies the requester by email since user ids differ between deployments.
+type ArchivedJoinRequest struct {
+	RequestId string     `json:"request_id"`
+	UserEmail string     `json:"user_email"`
+	UserName  string     `json:"user_name"`
+	Message   string     `json:"message"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	DecidedBy string     `json:"decided_by,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
+// ArchivedServiceAccount keeps the secret hash so integrations keep their credentials after a migration.
+type ArchivedServiceAccount struct {
+	ClientId     string     `json:"client_id"`
+	Name         string     `json:"name"`
+	AccessLevel  string     `json:"access_level"`
+	HashedSecret string     `json:"hashed_secret"`
+	Disabled     bool       `json:"disabled"`
+	CreatedAt    time.Time  `json:"created_at"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+}
+
+// ArchivedAuditEvent is an event of the organization's audit trail. Actor ids refer to the deployment that
+// recorded the event; the actor email identifies the administrator across deployments.
+type ArchivedAuditEvent struct {
+	EventId    string                 `json:"event_id"`
+	ActorId    string                 `json:"actor_id"`
+	ActorEmail string                 `json:"actor_email"`
+	Action     string                 `json:"action"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	ClientIP   string                 `json:"client_ip"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// DecodeOrganizationArchive reads an archive and checks that this version of the service understands it.
+func DecodeOrganizationArchive(r io.Reader) (*OrganizationArchive, error) {
+	var archive OrganizationArchive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("reading organization archive: %w", err)
+	}
+	if archive.Version != OrganizationArchiveVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedArchiveVersion, archive.Version)
+	}
+	if archive.Organization.Name == "" {
+		return nil, errors.New("Organization archive has no organization name")
+	}
+	return &archive, nil
+}
+
+// ValidArchiveConflict reports whether mode is one of the supported conflict modes.
+func ValidArchiveConflict(mode string) bool {
+	return mode == ArchiveConflictFail || mode == ArchiveConflictSkip || mode == ArchiveConflictRename
+}
@@ -0,0 +1,205 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms, as selected by the password_hashing.algorithm setting.
+const (
+	PasswordHashArgon2id = "argon2id"
+	PasswordHashBcrypt   = "bcrypt"
+)
+
+var (
+	ErrPasswordMismatch          = errors.New("Password doesn't match")
+	ErrUnknownPasswordHash       = errors.New("Unknown password hash format")
+	ErrUnsupportedPasswordHasher = errors.New("Password hash algorithm must be argon2id or bcrypt")
+)
+
+// PasswordHasher hashes new passwords with one algorithm and set of parameters. Hashes are self-describing:
+// they start with the algorithm identifier and carry their parameters, so they can be verified and
+// recognized as outdated after the configuration changes.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify returns ErrPasswordMismatch when the password doesn't match the hash.
+	Verify(password, encoded string) error
+	// Handles reports whether the hash was produced by this algorithm, with any parameters.
+	Handles(encoded string) bool
+	// Current reports whether the hash was produced with exactly this hasher's parameters.
+	Current(encoded string) bool
+}
+
+// Argon2idHasher hashes passwords with Argon2id and stores them in the PHC string format
+// $argon2id$v=19$m=<memory KiB>,t=<iterations>,p=<parallelism>$<salt>$<key>.
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+type argon2idParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *Argon2idHasher) Verify(password, encoded string) error {
+	params, err := parseArgon2id(encoded)
+	if err != nil {
+		return err
+	}
+	key := argon2.IDKey([]byte(password), params.salt, params.iterations, params.memory, params.parallelism, uint32(len(params.key)))
+	if subtle.ConstantTimeCompare(key, params.key) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+func (h *Argon2idHasher) Handles(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (h *Argon2idHasher) Current(encoded string) bool {
+	params, err := parseArgon2id(encoded)
+	return err == nil && params.memory == h.Memory && params.iterations == h.Iterations &&
+		params.parallelism == h.Parallelism && uint32(len(params.salt)) == h.SaltLength && uint32(len(params.key)) == h.KeyLength
+}
+
+func parseArgon2id(encoded string) (*argon2idParams, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrUnknownPasswordHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, ErrUnknownPasswordHash
+	}
+	params := &argon2idParams{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return nil, ErrUnknownPasswordHash
+	}
+	var err error
+	if params.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, ErrUnknownPasswordHash
+	}
+	if params.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(params.key) == 0 {
+		return nil, ErrUnknownPasswordHash
+	}
+	return params, nil
+}
+
+// BcryptHasher hashes passwords with bcrypt, whose hashes start with $2a$, $2b$ or $2y$ and carry their cost.
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(hashed), err
+}
+
+func (h *BcryptHasher) Verify(password, encoded string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrPasswordMismatch
+	}
+	return err
+}
+
+func (h *BcryptHasher) Handles(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (h *BcryptHasher) Current(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err == nil && cost == h.Cost
+}
+
+// PasswordHashing hashes new passwords with the preferred hasher and verifies hashes of every supported
+// algorithm, so stored hashes keep working while they are upgraded.
+type PasswordHashing struct {
+	Preferred PasswordHasher
+	Supported []PasswordHasher
+}
+
+// PasswordHashingSettings are the values a PasswordHashing is built from.
+type PasswordHashingSettings struct {
+	Algorithm         string
+	Argon2MemoryKiB   uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	BcryptCost        int
+}
+
+// NewPasswordHashing builds the hashers from settings, preferring the selected algorithm for new hashes.
+func NewPasswordHashing(settings PasswordHashingSettings) (*PasswordHashing, error) {
+	// argon2 panics on zero iterations or lanes, so they are refused here as well as in the config
+	if settings.Argon2Iterations == 0 || settings.Argon2Parallelism == 0 {
+		return nil, errors.New("Argon2id iterations and parallelism must be positive")
+	}
+	if settings.BcryptCost < bcrypt.MinCost || settings.BcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	argon2id := &Argon2idHasher{
+		Memory:      settings.Argon2MemoryKiB,
+		Iterations:  settings.Argon2Iterations,
+		Parallelism: settings.Argon2Parallelism,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+	bcryptHasher := &BcryptHasher{Cost: settings.BcryptCost}
+
+	hashing := &PasswordHashing{Supported: []PasswordHasher{argon2id, bcryptHasher}}
+	switch settings.Algorithm {
+	case PasswordHashArgon2id:
+		hashing.Preferred = argon2id
+	case PasswordHashBcrypt:
+		hashing.Preferred = bcryptHasher
+	default:
+		return nil, ErrUnsupportedPasswordHasher
+	}
+	return hashing, nil
+}
+
+// Hash hashes a new password with the preferred hasher.
+func (p *PasswordHashing) Hash(password string) (string, error) {
+	return p.Preferred.Hash(password)
+}
+
+// Verify checks the password against a hash of any supported algorithm.
+func (p *PasswordHashing) Verify(password, encoded string) error {
+	for _, hasher := range p.Supported {
+		if hasher.Handles(encoded) {
+			return hasher.Verify(password, encoded)
+		}
+	}
+	return ErrUnknownPasswordHash
+}
+
+// NeedsRehash reports whether the hash was produced with another algorithm or outdated parameters.
+func (p *PasswordHashing) NeedsRehash(encoded string) bool {
+	return !p.Preferred.Handles(encoded) || !p.Preferred.Current(encoded)
+}
@@ -0,0 +1,210 @@
+package util
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rules a password can break, reported in PasswordViolation.Rule.
+const (
+	PasswordRuleMinLength    = "min_length"
+	PasswordRuleMaxLength    = "max_length"
+	PasswordRuleUppercase    = "uppercase"
+	PasswordRuleLowercase    = "lowercase"
+	PasswordRuleDigit        = "digit"
+	PasswordRuleSymbol       = "symbol"
+	PasswordRuleContainsUser = "contains_user_info"
+	PasswordRuleBreached     = "breached"
+)
+
+// bcryptMaxBytes is the longest password bcrypt can hash.
+const bcryptMaxBytes = 72
+
+// minUserInfoLength is the shortest part of an email or name that passwords may not contain, so that
+// short names like "Al" don't rule out half of all passwords.
+const minUserInfoLength = 3
+
+// PasswordPolicy describes what a new password must look like.
+type PasswordPolicy struct {
+	MinLength        int
+	MaxLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	DisallowUserInfo bool
+	// Breached, when set, rejects passwords that appear in a list of breached passwords.
+	Breached BreachedPasswordChecker
+}
+
+// PasswordViolation describes a rule a password broke.
+type PasswordViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// BreachedPasswordChecker reports whether a password is known from a data breach.
+type BreachedPasswordChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+// PasswordPolicySettings are the values a PasswordPolicy is built from. The breached password check is
+// enabled by pointing BreachedPasswordsDir at a range directory.
+type PasswordPolicySettings struct {
+	MinLength                int
+	MaxLength                int
+	RequireUppercase         bool
+	RequireLowercase         bool
+	RequireDigit             bool
+	RequireSymbol            bool
+	DisallowUserInfo         bool
+	BreachedPasswordsDir     string
+	BreachedPasswordMinCount int
+}
+
+// NewPasswordPolicy builds a policy from settings, refusing lengths bcrypt couldn't hash.
+func NewPasswordPolicy(settings PasswordPolicySettings) (PasswordPolicy, error) {
+	if settings.MinLength < 1 || settings.MaxLength < settings.MinLength || settings.MaxLength > bcryptMaxBytes {
+		return PasswordPolicy{}, fmt.Errorf("Password lengths must be between 1 and %d bytes", bcryptMaxBytes)
+	}
+	policy := PasswordPolicy{
+		MinLength:        settings.MinLength,
+		MaxLength:        settings.MaxLength,
+		RequireUppercase: settings.RequireUppercase,
+		RequireLowercase: settings.RequireLowercase,
+		RequireDigit:     settings.RequireDigit,
+		RequireSymbol:    settings.RequireSymbol,
+		DisallowUserInfo: settings.DisallowUserInfo,
+	}
+	if settings.BreachedPasswordsDir != "" {
+		if settings.BreachedPasswordMinCount < 1 {
+			return PasswordPolicy{}, errors.New("Breached password minimum count must be positive")
+		}
+		policy.Breached = &BreachedPasswordRanges{Dir: settings.BreachedPasswordsDir, MinCount: settings.BreachedPasswordMinCount}
+	}
+	return policy, nil
+}
+
+// Check returns every rule the password breaks for the user with the given email and name.
+// An error means the breached password list couldn't be read.
+func (p PasswordPolicy) Check(password, email, name string) ([]PasswordViolation, error) {
+	violations := []PasswordViolation{}
+	add := func(rule, message string) {
+		violations = append(violations, PasswordViolation{Rule: rule, Message: message})
+	}
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		add(PasswordRuleMinLength, fmt.Sprintf("Password must be at least %d characters long", p.MinLength))
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		add(PasswordRuleMaxLength, fmt.Sprintf("Password must be at most %d bytes long", p.MaxLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUppercase && !upper {
+		add(PasswordRuleUppercase, "Password must contain an uppercase letter")
+	}
+	if p.RequireLowercase && !lower {
+		add(PasswordRuleLowercase, "Password must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		add(PasswordRuleDigit, "Password must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		add(PasswordRuleSymbol, "Password must contain a symbol")
+	}
+
+	if p.DisallowUserInfo && containsUserInfo(password, email, name) {
+		add(PasswordRuleContainsUser, "Password must not contain your email address or name")
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.IsBreached(password)
+		if err != nil {
+			return nil, err
+		}
+		if breached {
+			add(PasswordRuleBreached, "Password appears in a known data breach, choose a different one")
+		}
+	}
+	return violations, nil
+}
+
+// containsUserInfo reports whether the password contains the email, its local part or a word of the name.
+func containsUserInfo(password, email, name string) bool {
+	password = strings.ToLower(password)
+	parts := []string{strings.ToLower(email)}
+	if local, _, ok := strings.Cut(parts[0], "@"); ok {
+		parts = append(parts, local)
+	}
+	parts = append(parts, strings.Fields(strings.ToLower(name))...)
+	for _, part := range parts {
+		if utf8.RuneCountInString(part) >= minUserInfoLength && strings.Contains(password, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// BreachedPasswordRanges checks passwords against a local copy of a breached password list split by
+// k-anonymity prefix, in the layout of the Pwned Passwords range API: Dir holds one file per five
+// character SHA-1 prefix, named like 21BD1.txt, listing the remaining 35 characters and a count per
+// line as SUFFIX:COUNT. Only the file for the password's prefix is read.
+type BreachedPasswordRanges struct {
+	Dir string
+	// MinCount ignores hashes seen fewer times than this in breaches.
+	MinCount int
+}
+
+// IsBreached reports whether the password's hash is listed. A missing prefix file means no hash with
+// that prefix is known.
+func (b *BreachedPasswordRanges) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := os.Open(filepath.Join(b.Dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		listed, count, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !strings.EqualFold(listed, suffix) {
+			continue
+		}
+		seen, err := strconv.Atoi(count)
+		if err != nil {
+			// Lists without counts only contain hashes that were seen
+			seen = 1
+		}
+		return seen >= b.MinCount, nil
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"time"
+)
+
+// RateLimit allows Requests per Window. A zero Requests disables the limit.
+type RateLimit struct {
+	Requests int64
+	Window   time.Duration
+}
+
+// RateLimitPolicy holds the limits applied to a route group.
+type RateLimitPolicy struct {
+	Name    string
+	PerIP   RateLimit
+	PerUser RateLimit
+}
+
+// NewRateLimitPolicy returns the limits of a route group, allowing ipRequests per client address and
+// userRequests per user in each window. Setting a number of requests to 0 turns that limit off.
+func NewRateLimitPolicy(name string, ipRequests, userRequests int64, window time.Duration) RateLimitPolicy {
+	return RateLimitPolicy{
+		Name:    name,
+		PerIP:   RateLimit{Requests: ipRequests, Window: window},
+		PerUser: RateLimit{Requests: userRequests, Window: window},
+	}
+}
@@ -0,0 +1,83 @@
+package util
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	SCIMUserSchema         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMGroupSchema        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMListResponseSchema = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMPatchOpSchema      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMErrorSchema        = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	// SCIMContentType is the media type of every SCIM response.
+	SCIMContentType = "application/scim+json"
+)
+
+var ErrInvalidSCIMFilter = errors.New("Unsupported or malformed filter")
+
+// SCIMFilter is a single attribute comparison such as userName eq "jane@example.com".
+type SCIMFilter struct {
+	Attribute string
+	Operator  string
+	Value     string
+}
+
+var scimFilterPattern = regexp.MustCompile(`^\s*([A-Za-z][\w.]*)\s+(eq|co|sw)\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// ParseSCIMFilter parses the subset of SCIM filters this service supports: one attribute compared with
+// eq, co or sw against a quoted string. An empty filter returns nil.
+func ParseSCIMFilter(filter string) (*SCIMFilter, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+	match := scimFilterPattern.FindStringSubmatch(filter)
+	if match == nil {
+		return nil, ErrInvalidSCIMFilter
+	}
+	return &SCIMFilter{
+		Attribute: match[1],
+		Operator:  strings.ToLower(match[2]),
+		Value:     strings.ReplaceAll(match[3], `\"`, `"`),
+	}, nil
+}
+
+// Pattern returns a case-insensitive regular expression matching the filter's value with its operator.
+func (f *SCIMFilter) Pattern() string {
+	quoted := regexp.QuoteMeta(f.Value)
+	switch f.Operator {
+	case "co":
+		return "(?i)" + quoted
+	case "sw":
+		return "(?i)^" + quoted
+	default:
+		return "(?i)^" + quoted + "$"
+	}
+}
+
+// SCIMPatchRequest is the body of a SCIM PATCH request.
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+// SCIMPatchOperation is a single add, remove or replace operation.
+type SCIMPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+var scimMemberPathPattern = regexp.MustCompile(`^members\[\s*value\s+eq\s+"([^"]+)"\s*\]$`)
+
+// ParseSCIMMemberPath extracts the member id from a path such as members[value eq "id"].
+func ParseSCIMMemberPath(path string) (string, bool) {
+	match := scimMemberPathPattern.FindStringSubmatch(strings.TrimSpace(path))
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
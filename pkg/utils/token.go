@@ -1,70 +1,298 @@
 package util
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"strconv"
 	"strings"
 	"time"
-	"errors"
 
 	"github.com/gin-gonic/gin"
-	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
 )
 
-// GenerateToken generates a JWT access and refresh token for the given user ID and email.
-func GenerateToken(userID uint, email string) (string, string, error) {
-    tokenLifespan, err := strconv.Atoi(os.Getenv("TOKEN_HOUR_LIFESPAN"))
-    if err != nil {
-        return "", "", err
-    }
-
-    // Create JWT claims for access token
-    accessClaims := jwt.MapClaims{}
-    accessClaims["authorized"] = true
-    accessClaims["user_id"] = userID
-    accessClaims["email"] = email
-    accessClaims["exp"] = time.Now().Add(time.Hour * time.Duration(tokenLifespan)).Unix()
-
-    // Create access token with claims and sign with secret
-    accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-    accessTokenString, err := accessToken.SignedString([]byte(os.Getenv("API_SECRET")))
-    if err != nil {
-        return "", "", err
-    }
-
-    // Create JWT claims for refresh token
-    refreshClaims := jwt.MapClaims{}
-    refreshClaims["authorized"] = true
-    refreshClaims["user_id"] = userID
-    refreshClaims["email"] = email
-    refreshClaims["exp"] = time.Now().Add(time.Hour * 24 * 30).Unix() // Set refresh token expiration to 30 days
-
-    // Create refresh token with claims and sign with secret
-    refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-    refreshTokenString, err := refreshToken.SignedString([]byte(os.Getenv("API_SECRET")))
-    if err != nil {
-        return "", "", err
-    }
-
-    return accessTokenString, refreshTokenString, nil
-}
-
-
-// TokenValid checks if the JWT token provided in the request is valid.
-func TokenValid(c *gin.Context) error {
-	// Extract token string from request
-	tokenString := ExtractToken(c)
-
-	// Parse and validate token
-	_, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Check signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+const (
+	// TokenTypeAccess marks a token that may be presented to protected routes.
+	TokenTypeAccess = "access"
+	// TokenTypeRefresh marks a token that may only be exchanged for a new token pair.
+	TokenTypeRefresh = "refresh"
+	// TokenTypeAPIKey marks claims built from a personal API key rather than a signed token.
+	TokenTypeAPIKey = "api_key"
+	// TokenTypeServiceAccount marks a short-lived token issued to an organization's service account.
+	TokenTypeServiceAccount = "service_account"
+	// TokenTypeClientAccess marks an access token issued to an OpenID Connect client application. It is bound
+	// to the client as audience and only grants the scopes the user consented to at the userinfo endpoint.
+	TokenTypeClientAccess = "client_access"
+
+	// ClaimsContextKey is the gin context key under which the middleware stores verified claims.
+	ClaimsContextKey = "token_claims"
+
+	defaultTokenIssuer   = "organization_management"
+	defaultTokenAudience = "organization_management_api"
+	refreshTokenLifespan = 30 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidToken   = errors.New("Invalid token")
+	ErrWrongTokenType = errors.New("Unexpected token type")
+)
+
+// TokenClaims are the claims carried by both access and refresh tokens.
+// TokenType keeps the two apart so a refresh token is never accepted as an access token.
+type TokenClaims struct {
+	Email          string      `json:"email"`
+	TokenType      string      `json:"token_type"`
+	Scopes         []string    `json:"scopes,omitempty"`
+	OrganizationId string      `json:"organization_id,omitempty"`
+	Actor          *TokenActor `json:"act,omitempty"`
+	// IssuedAtMicros is when the token was issued, in microseconds since the epoch. iat only has second
+	// precision, which can't tell a token issued just before a revocation from one issued just after.
+	IssuedAtMicros int64 `json:"iat_us,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// TokenActor identifies the administrator acting as the token's subject, following the "act" claim of RFC 8693.
+type TokenActor struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email"`
+}
+
+// UserID returns the subject of the token, which is the user's id.
+func (c *TokenClaims) UserID() string {
+	return c.Subject
+}
+
+// IssueTime returns when the token was issued. Tokens signed before iat_us existed only have the second,
+// which makes them count as revoked by a revocation later in that second.
+func (c *TokenClaims) IssueTime() time.Time {
+	if c.IssuedAtMicros != 0 {
+		return time.UnixMicro(c.IssuedAtMicros)
+	}
+	if c.IssuedAt == nil {
+		return time.Time{}
+	}
+	return c.IssuedAt.Time
+}
+
+// IsImpersonated reports whether an administrator is acting as the subject with this token.
+func (c *TokenClaims) IsImpersonated() bool {
+	return c.Actor != nil
+}
+
+// HasScope reports whether the credential grants scope. Access tokens without scopes grant everything.
+func (c *TokenClaims) HasScope(scope string) bool {
+	if c.TokenType == TokenTypeAccess && len(c.Scopes) == 0 {
+		return true
+	}
+	for _, granted := range c.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessOrganization reports whether the credential may act on the organization.
+// Service account tokens are bound to the organization that owns them.
+func (c *TokenClaims) CanAccessOrganization(organizationID string) bool {
+	return c.OrganizationId == "" || c.OrganizationId == organizationID
+}
+
+// TokenVerifier signs and validates tokens with a shared secret and the expected issuer and audience.
+type TokenVerifier struct {
+	secret         []byte
+	issuer         string
+	audience       string
+	leeway         time.Duration
+	accessLifespan time.Duration
+}
+
+// TokenSettings are the values a TokenVerifier is built from.
+type TokenSettings struct {
+	Secret   string
+	Lifespan time.Duration
+	Leeway   time.Duration
+	Issuer   string
+	Audience string
+}
+
+// NewTokenVerifierFromSettings builds a verifier from explicit settings, filling in the default issuer and audience.
+func NewTokenVerifierFromSettings(settings TokenSettings) (*TokenVerifier, error) {
+	if settings.Secret == "" {
+		return nil, errors.New("Token secret is not set")
+	}
+	if settings.Lifespan <= 0 {
+		return nil, errors.New("Token lifespan must be positive")
+	}
+	if settings.Issuer == "" {
+		settings.Issuer = defaultTokenIssuer
+	}
+	if settings.Audience == "" {
+		settings.Audience = defaultTokenAudience
+	}
+	return &TokenVerifier{
+		secret:         []byte(settings.Secret),
+		issuer:         settings.Issuer,
+		audience:       settings.Audience,
+		leeway:         settings.Leeway,
+		accessLifespan: settings.Lifespan,
+	}, nil
+}
+
+// Sign issues a token of the given type for the user.
+func (v *TokenVerifier) Sign(userID, email, tokenType string, lifespan time.Duration) (string, error) {
+	return v.SignClaims(TokenClaims{Email: email, TokenType: tokenType}, userID, lifespan)
+}
+
+// SignClaims fills in the registered claims for subject and signs the token.
+func (v *TokenVerifier) SignClaims(claims TokenClaims, subject string, lifespan time.Duration) (string, error) {
+	return v.signFor(claims, subject, v.audience, lifespan)
+}
+
+// SignClientAccessToken issues an access token for the user to an OpenID Connect client, limited to scopes.
+func (v *TokenVerifier) SignClientAccessToken(userID, email, clientID string, scopes []string) (string, error) {
+	claims := TokenClaims{Email: email, TokenType: TokenTypeClientAccess, Scopes: scopes}
+	return v.signFor(claims, userID, clientID, v.accessLifespan)
+}
+
+func (v *TokenVerifier) signFor(claims TokenClaims, subject, audience string, lifespan time.Duration) (string, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    v.issuer,
+		Subject:   subject,
+		Audience:  jwt.ClaimStrings{audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(lifespan)),
+	}
+	claims.IssuedAtMicros = now.UnixMicro()
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(v.secret)
+}
+
+// Verify parses the token, checks the signature and the standard claims, and makes sure it has one of the expected types.
+func (v *TokenVerifier) Verify(tokenString string, expectedTypes ...string) (*TokenClaims, error) {
+	claims, err := v.parse(tokenString, jwt.WithAudience(v.audience))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTokenType(claims, expectedTypes); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// VerifyClientAccessToken validates an access token issued to an OpenID Connect client. Its audience is the
+// client rather than this API, which is why it is never accepted by Verify.
+func (v *TokenVerifier) VerifyClientAccessToken(tokenString string) (*TokenClaims, error) {
+	claims, err := v.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if len(claims.Audience) != 1 || claims.Audience[0] == v.audience {
+		return nil, ErrInvalidToken
+	}
+	if err := checkTokenType(claims, []string{TokenTypeClientAccess}); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *TokenVerifier) parse(tokenString string, options ...jwt.ParserOption) (*TokenClaims, error) {
+	claims := &TokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return v.secret, nil
+	}, append([]jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithLeeway(v.leeway),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	}, options...)...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !token.Valid || claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func checkTokenType(claims *TokenClaims, expectedTypes []string) error {
+	for _, expectedType := range expectedTypes {
+		if claims.TokenType == expectedType {
+			return nil
 		}
-		return []byte(os.Getenv("API_SECRET")), nil
-	})
-	return err
+	}
+	return ErrWrongTokenType
+}
+
+// MaxTokenLifespan is the longest any issued token stays valid, which bounds how long revocations must be remembered.
+func MaxTokenLifespan() time.Duration {
+	return refreshTokenLifespan
+}
+
+// GenerateToken generates a JWT access and refresh token for the given user ID and email.
+func (v *TokenVerifier) GenerateToken(userID string, email string) (string, string, error) {
+	accessToken, err := v.Sign(userID, email, TokenTypeAccess, v.accessLifespan)
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err := v.Sign(userID, email, TokenTypeRefresh, refreshTokenLifespan)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// GenerateAccessToken generates only an access token, for flows that don't hand out refresh tokens.
+func (v *TokenVerifier) GenerateAccessToken(userID string, email string) (string, time.Duration, error) {
+	token, err := v.Sign(userID, email, TokenTypeAccess, v.accessLifespan)
+	return token, v.accessLifespan, err
+}
+
+// GenerateClientAccessToken issues an access token to an OpenID Connect client for the scopes the user granted.
+func (v *TokenVerifier) GenerateClientAccessToken(userID, email, clientID string, scopes []string) (string, time.Duration, error) {
+	token, err := v.SignClientAccessToken(userID, email, clientID, scopes)
+	return token, v.accessLifespan, err
+}
+
+// VerifyAccessToken validates an access token and returns its claims.
+func (v *TokenVerifier) VerifyAccessToken(tokenString string) (*TokenClaims, error) {
+	return v.Verify(tokenString, TokenTypeAccess)
+}
+
+// VerifyBearerToken validates a token presented to protected routes, which is either a user's access token
+// or a service account token, and returns its claims.
+func (v *TokenVerifier) VerifyBearerToken(tokenString string) (*TokenClaims, error) {
+	return v.Verify(tokenString, TokenTypeAccess, TokenTypeServiceAccount)
+}
+
+// GenerateServiceAccountToken issues a short-lived token for a service account of an organization.
+func (v *TokenVerifier) GenerateServiceAccountToken(clientID, email, organizationID string, scopes []string, lifespan time.Duration) (string, error) {
+	claims := TokenClaims{
+		Email:          email,
+		TokenType:      TokenTypeServiceAccount,
+		Scopes:         scopes,
+		OrganizationId: organizationID,
+	}
+	return v.SignClaims(claims, clientID, lifespan)
+}
+
+// GenerateImpersonationToken issues a short-lived access token for a user that records the administrator
+// acting as them. No refresh token is issued, so impersonation ends when the token expires.
+func (v *TokenVerifier) GenerateImpersonationToken(userID, email string, actor TokenActor, lifespan time.Duration) (string, error) {
+	claims := TokenClaims{
+		Email:     email,
+		TokenType: TokenTypeAccess,
+		Actor:     &actor,
+	}
+	return v.SignClaims(claims, userID, lifespan)
+}
+
+// VerifyRefreshToken validates a refresh token and returns its claims.
+func (v *TokenVerifier) VerifyRefreshToken(tokenString string) (*TokenClaims, error) {
+	return v.Verify(tokenString, TokenTypeRefresh)
 }
 
 // ExtractToken extracts the JWT token from the request.
@@ -85,25 +313,33 @@ func ExtractToken(c *gin.Context) string {
 	return ""
 }
 
+// ExtractClaims returns the claims verified by the middleware. Requests on routes without it have none.
+func ExtractClaims(c *gin.Context) (*TokenClaims, error) {
+	if claims, ok := AuthenticatedClaims(c); ok {
+		return claims, nil
+	}
+	return nil, ErrInvalidToken
+}
+
+// AuthenticatedClaims returns the claims the middleware stored for the request, without verifying the
+// request token itself, for routes where authentication is optional.
+func AuthenticatedClaims(c *gin.Context) (*TokenClaims, bool) {
+	value, ok := c.Get(ClaimsContextKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := value.(*TokenClaims)
+	return claims, ok
+}
+
 // ExtractUserEmail extracts the user email from the JWT token claims.
 func ExtractUserEmail(c *gin.Context) (string, error) {
-    tokenString := ExtractToken(c)
-    token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-        }
-        return []byte(os.Getenv("API_SECRET")), nil
-    })
-    if err != nil {
-        return "", err
-    }
-    claims, ok := token.Claims.(jwt.MapClaims)
-    if !ok || !token.Valid {
-        return "", errors.New("Invalid token claims")
-    }
-    email, ok := claims["email"].(string)
-    if !ok {
-        return "", errors.New("Email claim not found")
-    }
-    return email, nil
+	claims, err := ExtractClaims(c)
+	if err != nil {
+		return "", err
+	}
+	if claims.Email == "" {
+		return "", errors.New("Email claim not found")
+	}
+	return claims.Email, nil
 }
@@ -9,7 +9,6 @@ import (
 
     "github.com/gin-gonic/gin"
     "github.com/stretchr/testify/assert"
-    controller "organization_management/pkg/controllers"
 )
 
 func TestRegisterUserEndpoint(t *testing.T) {
@@ -31,7 +30,7 @@ func TestRegisterUserEndpoint(t *testing.T) {
 
     c.Request = req
 
-    controller.RegisterUser()(c)
+    testHandler(t).RegisterUser()(c)
 
     assert.Equal(t, http.StatusCreated, w.Code)
 
@@ -61,7 +60,7 @@ func TestEmailValidation(t *testing.T) {
     c, _ := gin.CreateTestContext(w)
     c.Request = req
 
-    handler := controller.RegisterUser()
+    handler := testHandler(t).RegisterUser()
 
     handler(c)
 
@@ -103,7 +102,7 @@ func TestRegisterUserWithMissingField(t *testing.T) {
 
     c.Request = req
 
-    handler := controller.RegisterUser()
+    handler := testHandler(t).RegisterUser()
 
     handler(c)
 
@@ -0,0 +1,140 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	model "organization_management/pkg/database/mongodb/models"
+	util "organization_management/pkg/utils"
+)
+
+// seedJoinRequest stores a user, an organization founded by founderEmail and the user's pending request to join it.
+func seedJoinRequest(t *testing.T, ctx context.Context, founderEmail string, members ...model.OrganizationMember) (*model.User, model.JoinRequest) {
+	t.Helper()
+	store := testApp(t).Store
+
+	email := "joiner-" + uuid.New().String() + "@example.com"
+	_, err := store.InsertUser(ctx, model.User{Name: "Joiner", Email: email, Status: model.UserStatusActive})
+	require.NoError(t, err)
+	user, err := store.GetUserByEmail(ctx, email)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.DeleteUser(context.Background(), user.Id) })
+
+	members = append(members, model.OrganizationMember{
+		Name: "Founder", UserEmail: founderEmail, AccessLevel: model.AccessLevelFounder, Type: model.MemberTypeUser,
+	})
+	_, orgID, err := store.InsertOrganization(ctx, model.Organization{
+		Name: "Join requests", Description: "e2e", Discoverable: true, OrganizationMembers: members,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		store.DeleteJoinRequestsByOrganizationID(context.Background(), orgID)
+		store.DeleteOrganization(context.Background(), orgID)
+	})
+
+	request := model.JoinRequest{
+		RequestId:      uuid.New().String(),
+		OrganizationId: orgID,
+		UserId:         user.Id.Hex(),
+		UserEmail:      user.Email,
+		UserName:       user.Name,
+		Status:         model.JoinRequestPending,
+		CreatedAt:      time.Now(),
+	}
+	require.NoError(t, store.InsertJoinRequest(ctx, request))
+	return user, request
+}
+
+func approveJoinRequest(t *testing.T, founderEmail string, request model.JoinRequest, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{
+		{Key: "organization_id", Value: request.OrganizationId},
+		{Key: "request_id", Value: request.RequestId},
+	}
+	c.Set(util.ClaimsContextKey, &util.TokenClaims{Email: founderEmail, TokenType: util.TokenTypeAccess})
+
+	testHandler(t).ApproveJoinRequest()(c)
+	return w
+}
+
+func TestApproveJoinRequestAddsTheMember(t *testing.T) {
+	store := testApp(t).Store
+	ctx := context.Background()
+	founderEmail := "founder-" + uuid.New().String() + "@example.com"
+	user, request := seedJoinRequest(t, ctx, founderEmail)
+
+	w := approveJoinRequest(t, founderEmail, request, `{"access_level":"admin"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	org, err := store.GetOrganizationByID(ctx, request.OrganizationId)
+	require.NoError(t, err)
+	member := org.FindMember(user.Email)
+	require.NotNil(t, member)
+	assert.Equal(t, model.AccessLevelAdmin, member.AccessLevel)
+
+	decided, err := store.GetJoinRequest(ctx, request.OrganizationId, request.RequestId)
+	require.NoError(t, err)
+	assert.Equal(t, model.JoinRequestApproved, decided.Status)
+	assert.Equal(t, founderEmail, decided.DecidedBy)
+
+	// A decided request can't be approved again
+	w = approveJoinRequest(t, founderEmail, request, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestApproveJoinRequestKeepsAnExistingMembership(t *testing.T) {
+	store := testApp(t).Store
+	ctx := context.Background()
+	founderEmail := "founder-" + uuid.New().String() + "@example.com"
+	user, request := seedJoinRequest(t, ctx, founderEmail)
+
+	// The user joined some other way while the request was pending
+	added, err := store.AddOrganizationMember(ctx, request.OrganizationId, model.OrganizationMember{
+		Name: user.Name, UserEmail: strings.ToUpper(user.Email), AccessLevel: model.AccessLevelAdmin, Type: model.MemberTypeUser,
+	})
+	require.NoError(t, err)
+	require.True(t, added)
+
+	w := approveJoinRequest(t, founderEmail, request, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	org, err := store.GetOrganizationByID(ctx, request.OrganizationId)
+	require.NoError(t, err)
+	assert.Len(t, org.OrganizationMembers, 2)
+	assert.Equal(t, model.AccessLevelAdmin, org.FindMember(user.Email).AccessLevel)
+}
+
+func TestRemoveAddedOrganizationMemberOnlyRemovesThatMember(t *testing.T) {
+	store := testApp(t).Store
+	ctx := context.Background()
+	founderEmail := "founder-" + uuid.New().String() + "@example.com"
+	user, request := seedJoinRequest(t, ctx, founderEmail)
+
+	member := model.OrganizationMember{
+		Name: user.Name, UserEmail: user.Email, AccessLevel: model.AccessLevelMember, Type: model.MemberTypeUser,
+	}
+	added, err := store.AddOrganizationMember(ctx, request.OrganizationId, member)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	require.NoError(t, store.RemoveAddedOrganizationMember(ctx, request.OrganizationId, member))
+	require.NoError(t, store.RemoveAddedOrganizationMember(ctx, request.OrganizationId, model.OrganizationMember{
+		UserEmail: founderEmail, AccessLevel: model.AccessLevelFounder, Type: model.MemberTypeUser,
+	}))
+
+	org, err := store.GetOrganizationByID(ctx, request.OrganizationId)
+	require.NoError(t, err)
+	assert.Nil(t, org.FindMember(user.Email))
+	assert.NotNil(t, org.FindMember(founderEmail))
+}
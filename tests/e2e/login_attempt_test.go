@@ -0,0 +1,39 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	repository_token "organization_management/pkg/database/redis/repository"
+)
+
+func TestFailureCounterAlwaysExpires(t *testing.T) {
+	ctx := context.Background()
+	redisClient := testApp(t).Redis
+	attempts := repository_token.NewLoginAttemptRepository(redisClient)
+	key := "e2e:" + uuid.New().String()
+	t.Cleanup(func() { attempts.Reset(context.Background(), key) })
+
+	failures, err := attempts.IncrementFailures(ctx, key, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), failures)
+	failures, err = attempts.IncrementFailures(ctx, key, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), failures)
+
+	ttl, err := redisClient.PTTL(ctx, "login_attempts:"+key).Result()
+	require.NoError(t, err)
+	assert.True(t, ttl > 0 && ttl <= time.Minute, "the window started with the first failure")
+
+	// A counter left without an expiry gets one on the next failure
+	require.NoError(t, redisClient.Persist(ctx, "login_attempts:"+key).Err())
+	_, err = attempts.IncrementFailures(ctx, key, time.Minute)
+	require.NoError(t, err)
+	ttl, err = redisClient.PTTL(ctx, "login_attempts:"+key).Result()
+	require.NoError(t, err)
+	assert.True(t, ttl > 0, "the counter expires again")
+}
@@ -0,0 +1,52 @@
+package e2e
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "testing"
+    "time"
+
+    "organization_management/pkg"
+    controller "organization_management/pkg/controllers"
+)
+
+var (
+    // app is connected to the configured MongoDB and Redis, or nil when they are not available.
+    app *pkg.App
+    // unavailable says why app could not connect.
+    unavailable error
+)
+
+// TestMain connects to the configured MongoDB and Redis before the suite runs. When they are not
+// available the suite still runs, so every test shows up as skipped rather than the run passing silently.
+func TestMain(m *testing.M) {
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    app, unavailable = pkg.OpenApp(ctx)
+    cancel()
+    if unavailable != nil {
+        fmt.Fprintf(os.Stderr, "e2e tests will be skipped: %v\n", unavailable)
+    }
+
+    code := m.Run()
+    if app != nil {
+        app.Close(context.Background())
+    }
+    os.Exit(code)
+}
+
+// testApp returns the connected app, or skips the test when MongoDB or Redis is not available.
+func testApp(t *testing.T) *pkg.App {
+    t.Helper()
+    if app == nil {
+        t.Skipf("skipping e2e test: %v", unavailable)
+    }
+    return app
+}
+
+// testHandler returns handlers bound to the connected app and its features.
+func testHandler(t *testing.T) *controller.Handler {
+    t.Helper()
+    a := testApp(t)
+    return controller.NewHandler(a.Store, a.Redis, a.Features)
+}
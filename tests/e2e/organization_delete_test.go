@@ -0,0 +1,83 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	model "organization_management/pkg/database/mongodb/models"
+)
+
+func deleteOrganizationRequest(t *testing.T, orgID string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodDelete, "/", nil)
+	c.Params = gin.Params{{Key: "organization_id", Value: orgID}}
+
+	testHandler(t).DeleteOrganization()(c)
+	return w
+}
+
+func TestDeleteOrganizationReleasesItsResources(t *testing.T) {
+	store := testApp(t).Store
+	ctx := context.Background()
+	now := time.Now()
+
+	_, orgID, err := store.InsertOrganization(ctx, model.Organization{Name: "Doomed", Description: "e2e"})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		store.DeleteServiceAccountsByOrganizationID(context.Background(), orgID)
+		store.DeleteInviteLinksByOrganizationID(context.Background(), orgID)
+	})
+
+	account := model.ServiceAccount{
+		ClientId: "sa_" + uuid.New().String(), OrganizationId: orgID, Name: "ci",
+		AccessLevel: model.AccessLevelMember, CreatedAt: now,
+	}
+	require.NoError(t, store.InsertServiceAccount(ctx, account))
+	require.NoError(t, store.InsertOrganizationDomain(ctx, model.OrganizationDomain{
+		OrganizationId: orgID, Domain: uuid.New().String() + ".example", CreatedAt: now,
+	}))
+	require.NoError(t, store.InsertInviteLink(ctx, model.InviteLink{
+		LinkId: uuid.New().String(), OrganizationId: orgID, HashedToken: uuid.New().String(),
+		AccessLevel: model.AccessLevelMember, CreatedAt: now, ExpiresAt: now.Add(time.Hour),
+	}))
+	require.NoError(t, store.InsertJoinRequest(ctx, model.JoinRequest{
+		RequestId: uuid.New().String(), OrganizationId: orgID, Status: model.JoinRequestPending, CreatedAt: now,
+	}))
+
+	w := deleteOrganizationRequest(t, orgID)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	org, err := store.GetOrganizationByID(ctx, orgID)
+	require.NoError(t, err)
+	assert.Nil(t, org, "the organization is gone")
+
+	disabled, err := store.GetServiceAccountByClientID(ctx, account.ClientId)
+	require.NoError(t, err)
+	require.NotNil(t, disabled)
+	assert.True(t, disabled.Disabled)
+
+	domains, err := store.GetOrganizationDomainsByOrganizationID(ctx, orgID)
+	require.NoError(t, err)
+	assert.Empty(t, domains)
+
+	links, err := store.GetActiveInviteLinksByOrganizationID(ctx, orgID)
+	require.NoError(t, err)
+	assert.Empty(t, links)
+
+	requests, err := store.GetJoinRequestsByOrganizationID(ctx, orgID, "")
+	require.NoError(t, err)
+	assert.Empty(t, requests)
+}
+
+func TestDeleteUnknownOrganization(t *testing.T) {
+	w := deleteOrganizationRequest(t, uuid.New().String())
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,40 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	model "organization_management/pkg/database/mongodb/models"
+	repository "organization_management/pkg/database/mongodb/repository"
+)
+
+func TestOnlyOneOrganizationVerifiesADomain(t *testing.T) {
+	store := testApp(t).Store
+	ctx := context.Background()
+	domain := uuid.New().String() + ".example"
+	first, second := uuid.New().String(), uuid.New().String()
+	for _, orgID := range []string{first, second} {
+		require.NoError(t, store.InsertOrganizationDomain(ctx, model.OrganizationDomain{
+			OrganizationId: orgID, Domain: domain, CreatedAt: time.Now(),
+		}))
+		orgID := orgID
+		t.Cleanup(func() { store.DeleteOrganizationDomainsByOrganizationID(context.Background(), orgID) })
+	}
+
+	require.NoError(t, store.MarkOrganizationDomainVerified(ctx, first, domain))
+	err := store.MarkOrganizationDomainVerified(ctx, second, domain)
+	assert.ErrorIs(t, err, repository.ErrDomainAlreadyVerified)
+
+	claim, err := store.GetVerifiedOrganizationDomain(ctx, domain)
+	require.NoError(t, err)
+	assert.Equal(t, first, claim.OrganizationId)
+
+	err = store.InsertOrganizationDomain(ctx, model.OrganizationDomain{
+		OrganizationId: uuid.New().String(), Domain: domain, Verified: true, CreatedAt: time.Now(),
+	})
+	assert.ErrorIs(t, err, repository.ErrDomainAlreadyVerified)
+}
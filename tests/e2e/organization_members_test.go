@@ -0,0 +1,117 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	model "organization_management/pkg/database/mongodb/models"
+	repository "organization_management/pkg/database/mongodb/repository"
+)
+
+// seedOrganization stores an organization with the given members and removes it when the test ends.
+func seedOrganization(t *testing.T, store *repository.Store, members ...model.OrganizationMember) string {
+	t.Helper()
+
+	_, orgID, err := store.InsertOrganization(context.Background(), model.Organization{
+		Name: "Members " + uuid.New().String(), Description: "e2e", OrganizationMembers: members,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { store.DeleteOrganization(context.Background(), orgID) })
+	return orgID
+}
+
+func userMember(email, accessLevel string) model.OrganizationMember {
+	return model.OrganizationMember{Name: email, UserEmail: email, AccessLevel: accessLevel, Type: model.MemberTypeUser}
+}
+
+func TestUpdateOrganizationKeepsMembersAddedMeanwhile(t *testing.T) {
+	store := testApp(t).Store
+	ctx := context.Background()
+	orgID := seedOrganization(t, store, userMember("founder@example.com", model.AccessLevelFounder))
+
+	stale, err := store.GetOrganizationByID(ctx, orgID)
+	require.NoError(t, err)
+
+	added, err := store.AddOrganizationMember(ctx, orgID, userMember("joiner@example.com", model.AccessLevelMember))
+	require.NoError(t, err)
+	require.True(t, added)
+
+	stale.Name = "Renamed " + uuid.New().String()
+	require.NoError(t, store.UpdateOrganization(ctx, stale))
+
+	org, err := store.GetOrganizationByID(ctx, orgID)
+	require.NoError(t, err)
+	assert.Equal(t, stale.Name, org.Name)
+	assert.NotNil(t, org.FindMember("joiner@example.com"), "the member who joined after the read is kept")
+}
+
+func TestTransferOrganizationOwnership(t *testing.T) {
+	store := testApp(t).Store
+	ctx := context.Background()
+	orgID := seedOrganization(t, store,
+		userMember("first@example.com", model.AccessLevelFounder),
+		userMember("second@example.com", model.AccessLevelFounder),
+		userMember("Heir@Example.com", model.AccessLevelMember),
+	)
+
+	require.NoError(t, store.TransferOrganizationOwnership(ctx, orgID, userMember("heir@example.com", "")))
+
+	org, err := store.GetOrganizationByID(ctx, orgID)
+	require.NoError(t, err)
+	assert.Len(t, org.OrganizationMembers, 3, "an existing member is promoted, not added again")
+	assert.True(t, org.IsSoleFounder("heir@example.com"))
+	assert.Equal(t, model.AccessLevelAdmin, org.FindMember("first@example.com").AccessLevel)
+	assert.Equal(t, model.AccessLevelAdmin, org.FindMember("second@example.com").AccessLevel)
+
+	// A user who isn't a member yet is added as the Founder
+	require.NoError(t, store.TransferOrganizationOwnership(ctx, orgID, userMember("outsider@example.com", "")))
+	org, err = store.GetOrganizationByID(ctx, orgID)
+	require.NoError(t, err)
+	assert.True(t, org.IsSoleFounder("outsider@example.com"))
+	assert.Equal(t, model.AccessLevelAdmin, org.FindMember("heir@example.com").AccessLevel)
+}
+
+func TestMembershipsMatchTheEmailInAnyCase(t *testing.T) {
+	store := testApp(t).Store
+	ctx := context.Background()
+	email := "leaver-" + uuid.New().String() + "@example.com"
+	orgID := seedOrganization(t, store,
+		userMember("founder@example.com", model.AccessLevelFounder),
+		userMember("Leaver-"+email[len("leaver-"):], model.AccessLevelMember),
+	)
+
+	orgs, err := store.GetOrganizationsByMemberEmail(ctx, email)
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	assert.Equal(t, orgID, orgs[0].OrganizationId)
+
+	require.NoError(t, store.RemoveMemberFromAllOrganizations(ctx, email))
+	org, err := store.GetOrganizationByID(ctx, orgID)
+	require.NoError(t, err)
+	assert.Nil(t, org.FindMember(email))
+	assert.NotNil(t, org.FindMember("founder@example.com"))
+}
+
+func TestRenameMemberEmailMatchesAnyCase(t *testing.T) {
+	store := testApp(t).Store
+	ctx := context.Background()
+	id := uuid.New().String()
+	orgID := seedOrganization(t, store,
+		userMember("founder@example.com", model.AccessLevelFounder),
+		userMember("Jane.Doe-"+id+"@Example.com", model.AccessLevelAdmin),
+	)
+
+	// SCIM renames with the email of the account, which may differ in case from the stored membership
+	require.NoError(t, store.RenameMemberEmail(ctx, "jane.doe-"+id+"@example.com", "jane.smith-"+id+"@example.com"))
+
+	org, err := store.GetOrganizationByID(ctx, orgID)
+	require.NoError(t, err)
+	renamed := org.FindMember("jane.smith-" + id + "@example.com")
+	require.NotNil(t, renamed)
+	assert.Equal(t, "jane.smith-"+id+"@example.com", renamed.UserEmail)
+	assert.Equal(t, model.AccessLevelAdmin, renamed.AccessLevel)
+	assert.Nil(t, org.FindMember("jane.doe-"+id+"@example.com"))
+}
@@ -0,0 +1,23 @@
+package e2e
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadUnknownOrganization(t *testing.T) {
+	h := testHandler(t)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Params = gin.Params{{Key: "organization_id", Value: uuid.New().String()}}
+
+	h.ReadOrganization()(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
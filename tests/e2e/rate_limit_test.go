@@ -0,0 +1,61 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	repository_token "organization_management/pkg/database/redis/repository"
+)
+
+func TestSlidingWindowAdmitsUpToTheLimit(t *testing.T) {
+	ctx := context.Background()
+	limiter := repository_token.NewRateLimitRepository(testApp(t).Redis)
+	key := "e2e:" + uuid.New().String()
+	window := 500 * time.Millisecond
+
+	first, err := limiter.Allow(ctx, key, 2, window)
+	require.NoError(t, err)
+	assert.True(t, first.Allowed)
+	assert.Equal(t, int64(1), first.Remaining)
+
+	second, err := limiter.Allow(ctx, key, 2, window)
+	require.NoError(t, err)
+	assert.True(t, second.Allowed)
+	assert.Equal(t, int64(0), second.Remaining)
+
+	denied, err := limiter.Allow(ctx, key, 2, window)
+	require.NoError(t, err)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, int64(2), denied.Limit)
+	assert.Equal(t, int64(0), denied.Remaining)
+	assert.True(t, denied.Reset > 0 && denied.Reset <= window, "reset is when the oldest request leaves the window")
+
+	// Denied requests aren't counted, so the window frees up once the admitted ones age out
+	time.Sleep(window + 50*time.Millisecond)
+	again, err := limiter.Allow(ctx, key, 2, window)
+	require.NoError(t, err)
+	assert.True(t, again.Allowed)
+	assert.Equal(t, int64(1), again.Remaining)
+}
+
+func TestSlidingWindowKeepsKeysApart(t *testing.T) {
+	ctx := context.Background()
+	limiter := repository_token.NewRateLimitRepository(testApp(t).Redis)
+	prefix := "e2e:" + uuid.New().String()
+
+	result, err := limiter.Allow(ctx, prefix+":a", 1, time.Second)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = limiter.Allow(ctx, prefix+":b", 1, time.Second)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = limiter.Allow(ctx, prefix+":a", 1, time.Second)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+}
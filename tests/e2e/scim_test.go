@@ -0,0 +1,97 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	controller "organization_management/pkg/controllers"
+	model "organization_management/pkg/database/mongodb/models"
+	util "organization_management/pkg/utils"
+)
+
+// seedSCIMUser stores an active user and removes it when the test ends.
+func seedSCIMUser(t *testing.T, h *controller.Handler, name string) *model.User {
+	t.Helper()
+	ctx := context.Background()
+
+	email := name + "-" + uuid.New().String() + "@example.com"
+	_, err := h.Store.InsertUser(ctx, model.User{Name: name, Email: email, Status: model.UserStatusActive})
+	require.NoError(t, err)
+	user, err := h.Store.GetUserByEmail(ctx, email)
+	require.NoError(t, err)
+	t.Cleanup(func() { h.Store.DeleteUser(context.Background(), user.Id) })
+	return user
+}
+
+// scimGroupRequest runs handler as the given administrator with a JSON body and the group id as path parameter.
+func scimGroupRequest(handler gin.HandlerFunc, admin *model.User, groupID, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "id", Value: groupID}}
+	claims := &util.TokenClaims{Email: admin.Email, TokenType: util.TokenTypeAccess}
+	claims.Subject = admin.Id.Hex()
+	c.Set(util.ClaimsContextKey, claims)
+
+	handler(c)
+	return w
+}
+
+func TestSCIMCreateGroupMakesTheAdministratorFounder(t *testing.T) {
+	h := testHandler(t)
+	ctx := context.Background()
+	admin := seedSCIMUser(t, h, "provisioner")
+	member := seedSCIMUser(t, h, "member")
+
+	w := scimGroupRequest(h.SCIMCreateGroup(), admin, "",
+		`{"displayName":"SCIM `+uuid.New().String()+`","members":[{"value":"`+member.Id.Hex()+`"}]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var group struct {
+		Id string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &group))
+	t.Cleanup(func() { h.Store.DeleteOrganization(context.Background(), group.Id) })
+
+	org, err := h.Store.GetOrganizationByID(ctx, group.Id)
+	require.NoError(t, err)
+	assert.True(t, org.IsSoleFounder(admin.Email))
+	assert.Equal(t, model.AccessLevelMember, org.FindMember(member.Email).AccessLevel)
+
+	// Replacing the members with none keeps the Founder
+	w = scimGroupRequest(h.SCIMReplaceGroup(), admin, group.Id, `{"displayName":"Renamed","members":[]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	org, err = h.Store.GetOrganizationByID(ctx, group.Id)
+	require.NoError(t, err)
+	assert.Len(t, org.OrganizationMembers, 1)
+	assert.True(t, org.IsSoleFounder(admin.Email))
+}
+
+func TestSCIMListUsersWithCountZeroReturnsOnlyTheTotal(t *testing.T) {
+	h := testHandler(t)
+	seedSCIMUser(t, h, "listed")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/Users?count=0", nil)
+	h.SCIMListUsers()(c)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var page struct {
+		TotalResults int64         `json:"totalResults"`
+		ItemsPerPage int           `json:"itemsPerPage"`
+		Resources    []interface{} `json:"Resources"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.GreaterOrEqual(t, page.TotalResults, int64(1))
+	assert.Equal(t, 0, page.ItemsPerPage)
+	assert.Empty(t, page.Resources)
+}
@@ -0,0 +1,32 @@
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	repository_token "organization_management/pkg/database/redis/repository"
+)
+
+func TestRevocationRejectsTokensIssuedEarlierInTheSameSecond(t *testing.T) {
+	ctx := context.Background()
+	tokens := repository_token.NewTokenRepository(testApp(t).Redis)
+	userID := uuid.New().String()
+
+	issuedBefore := time.Now()
+	time.Sleep(time.Millisecond)
+	require.NoError(t, tokens.RevokeAllTokens(ctx, userID, time.Minute))
+	time.Sleep(time.Millisecond)
+	issuedAfter := time.Now()
+
+	revoked, err := tokens.IsTokenRevoked(ctx, userID, issuedBefore)
+	require.NoError(t, err)
+	assert.True(t, revoked, "a token stolen just before the revocation stops working")
+
+	revoked, err = tokens.IsTokenRevoked(ctx, userID, issuedAfter)
+	require.NoError(t, err)
+	assert.False(t, revoked, "signing in again right after the revocation works")
+}
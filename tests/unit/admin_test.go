@@ -0,0 +1,58 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	model "organization_management/pkg/database/mongodb/models"
+	util "organization_management/pkg/utils"
+)
+
+func TestIsAdminEmail(t *testing.T) {
+	admins, err := util.NewAdminSettings(util.AdminSettings{
+		Emails:                []string{" ops@example.com", "Root@Example.com ", ""},
+		ImpersonationLifespan: 15 * time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"ops@example.com", "root@example.com"}, admins.Emails)
+	assert.True(t, admins.IsAdminEmail("OPS@example.com"))
+	assert.True(t, admins.IsAdminEmail("root@example.com"))
+	assert.False(t, admins.IsAdminEmail("user@example.com"))
+}
+
+func TestPlatformRole(t *testing.T) {
+	assert.True(t, model.IsValidPlatformRole(""))
+	assert.True(t, model.IsValidPlatformRole(model.PlatformRoleAdmin))
+	assert.False(t, model.IsValidPlatformRole("superuser"))
+
+	user := model.User{}
+	assert.False(t, user.IsPlatformAdmin())
+	user.PlatformRole = model.PlatformRoleAdmin
+	assert.True(t, user.IsPlatformAdmin())
+}
+
+func TestGeneratePasswordResetToken(t *testing.T) {
+	token, hashedToken, err := util.GeneratePasswordResetToken()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, util.PasswordResetTokenPrefix))
+	assert.Equal(t, hashedToken, util.HashPasswordResetToken(token))
+	assert.NotContains(t, hashedToken, token)
+
+	other, _, err := util.GeneratePasswordResetToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestImpersonationLifespan(t *testing.T) {
+	admins, err := util.NewAdminSettings(util.AdminSettings{ImpersonationLifespan: 5 * time.Minute})
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, admins.ImpersonationLifespan)
+
+	_, err = util.NewAdminSettings(util.AdminSettings{ImpersonationLifespan: -time.Minute})
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	util "organization_management/pkg/utils"
+)
+
+func TestGeneratedAPIKeyRoundTrip(t *testing.T) {
+	key, keyID, hashedKey, err := util.GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, strings.HasPrefix(key, util.APIKeyPrefix))
+	assert.NotContains(t, hashedKey, key)
+
+	parsedID, ok := util.ParseAPIKey(key)
+	assert.True(t, ok)
+	assert.Equal(t, keyID, parsedID)
+	assert.True(t, util.APIKeyMatches(key, hashedKey))
+	assert.False(t, util.APIKeyMatches(key+"x", hashedKey))
+}
+
+func TestParseAPIKeyRejectsJwt(t *testing.T) {
+	_, ok := util.ParseAPIKey("eyJhbGciOiJIUzI1NiJ9.e30.signature")
+	assert.False(t, ok)
+}
+
+func TestAPIKeyClaimsOnlyGrantTheirScopes(t *testing.T) {
+	claims := util.TokenClaims{TokenType: util.TokenTypeAPIKey, Scopes: []string{util.ScopeOrganizationsRead}}
+	assert.True(t, claims.HasScope(util.ScopeOrganizationsRead))
+	assert.False(t, claims.HasScope(util.ScopeOrganizationsWrite))
+
+	accessClaims := util.TokenClaims{TokenType: util.TokenTypeAccess}
+	assert.True(t, accessClaims.HasScope(util.ScopeOrganizationsWrite))
+}
+
+func TestAPIKeyCreatedWithAnAPIKeyDoesNotOutliveIt(t *testing.T) {
+	now := time.Now()
+	parentExpiry := now.Add(24 * time.Hour)
+	parent := &util.TokenClaims{
+		TokenType:        util.TokenTypeAPIKey,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(parentExpiry)},
+	}
+
+	assert.True(t, util.APIKeyExpiry(parent, now.AddDate(1, 0, 0)).Equal(parentExpiry.Truncate(time.Second)))
+	assert.Equal(t, now.Add(time.Hour), util.APIKeyExpiry(parent, now.Add(time.Hour)), "an earlier expiry is kept")
+
+	session := &util.TokenClaims{
+		TokenType:        util.TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(now.Add(15 * time.Minute))},
+	}
+	assert.Equal(t, now.AddDate(1, 0, 0), util.APIKeyExpiry(session, now.AddDate(1, 0, 0)), "keys may outlive a session")
+}
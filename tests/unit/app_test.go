@@ -0,0 +1,125 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"organization_management/pkg"
+	"organization_management/pkg/config"
+)
+
+func TestRouterBuildsWithoutConnections(t *testing.T) {
+	router := (&pkg.App{Config: config.Default()}).Router()
+
+	registered := map[string]bool{}
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+	assert.True(t, registered["POST /api/signin"])
+	assert.True(t, registered["GET /api/admin/audit-events"])
+	assert.True(t, registered["GET /scim/v2/Users"])
+	assert.False(t, registered["GET /metrics"], "metrics are served on their own port")
+}
+
+func TestMetricsRouterOnlyServesMetrics(t *testing.T) {
+	routes := (&pkg.App{Config: config.Default()}).MetricsRouter().Routes()
+	if assert.Len(t, routes, 1) {
+		assert.Equal(t, "GET /metrics", routes[0].Method+" "+routes[0].Path)
+	}
+}
+
+// freePort returns a port nothing listens on right now.
+func freePort(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+func TestServeDrainsRequestsBeforeDisconnecting(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.Port = freePort(t)
+	cfg.Server.MetricsPort = freePort(t)
+	cfg.Server.ShutdownDelaySeconds = 0
+	cfg.Server.ShutdownTimeoutSeconds = 10
+
+	// Nothing listens at these addresses; the MongoDB ping hangs until the readiness check times out,
+	// which keeps a /readyz request in flight while the server shuts down.
+	mongoClient, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &pkg.App{Config: cfg, Mongo: mongoClient, Redis: goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:1"})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- app.Serve(ctx)
+	}()
+
+	base := "http://127.0.0.1:" + cfg.Server.Port
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get(base + "/healthz")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+	metricsResp, err := http.Get("http://127.0.0.1:" + cfg.Server.MetricsPort + "/metrics")
+	if assert.NoError(t, err) {
+		metricsResp.Body.Close()
+		assert.Equal(t, http.StatusOK, metricsResp.StatusCode)
+	}
+
+	type readiness struct {
+		ShuttingDown bool `json:"shutting_down"`
+		Dependencies map[string]struct {
+			Error string `json:"error"`
+		} `json:"dependencies"`
+	}
+	inFlight := make(chan readiness, 1)
+	go func() {
+		var body readiness
+		resp, err := http.Get(base + "/readyz")
+		if err == nil {
+			json.NewDecoder(resp.Body).Decode(&body)
+			resp.Body.Close()
+		}
+		inFlight <- body
+	}()
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("Serve didn't return")
+	}
+
+	// The request started before shutdown finished, and the clients were still connected while it ran
+	body := <-inFlight
+	assert.True(t, body.ShuttingDown)
+	assert.NotContains(t, body.Dependencies["mongodb"].Error, mongo.ErrClientDisconnected.Error())
+	assert.NotContains(t, body.Dependencies["redis"].Error, "client is closed")
+
+	// Afterwards both listeners are closed and so are the clients
+	_, err = http.Get(base + "/healthz")
+	assert.Error(t, err)
+	_, err = http.Get("http://127.0.0.1:" + cfg.Server.MetricsPort + "/metrics")
+	assert.Error(t, err)
+	assert.ErrorIs(t, mongoClient.Ping(context.Background(), nil), mongo.ErrClientDisconnected)
+	assert.ErrorContains(t, app.Redis.Ping(context.Background()).Err(), "client is closed")
+}
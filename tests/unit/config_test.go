@@ -0,0 +1,242 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"organization_management/pkg/config"
+)
+
+func clearConfigEnv(t *testing.T) {
+	for _, key := range []string{
+		"PORT", "METRICS_PORT", "SERVER_READ_TIMEOUT_SECONDS", "SERVER_WRITE_TIMEOUT_SECONDS", "SERVER_IDLE_TIMEOUT_SECONDS",
+		"SERVER_SHUTDOWN_TIMEOUT_SECONDS", "SERVER_SHUTDOWN_DELAY_SECONDS", "MONGOURI", "MONGODB_DATABASE_NAME", "REDIS_ADDR", "REDIS_PASSWORD", "REDIS_DB",
+		"API_SECRET", "TOKEN_HOUR_LIFESPAN", "TOKEN_LEEWAY_SECONDS", "TOKEN_ISSUER", "TOKEN_AUDIENCE",
+		"TRACING_EXPORTER", "OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_SERVICE_NAME", "TRACING_SAMPLE_RATIO",
+		"PASSWORD_HASH_ALGORITHM", "PASSWORD_ARGON2_MEMORY_KIB", "PASSWORD_ARGON2_ITERATIONS", "PASSWORD_ARGON2_PARALLELISM", "PASSWORD_BCRYPT_COST",
+		"PASSWORD_MIN_LENGTH", "PASSWORD_MAX_LENGTH", "PASSWORD_REQUIRE_UPPERCASE", "PASSWORD_REQUIRE_LOWERCASE", "PASSWORD_REQUIRE_DIGIT",
+		"PASSWORD_REQUIRE_SYMBOL", "PASSWORD_DISALLOW_USER_INFO", "BREACHED_PASSWORDS_DIR", "BREACHED_PASSWORD_MIN_COUNT",
+		"LOGIN_MAX_ACCOUNT_ATTEMPTS", "LOGIN_MAX_IP_ATTEMPTS", "LOGIN_ATTEMPT_WINDOW_MINUTES", "LOGIN_LOCKOUT_MINUTES",
+		"RATE_LIMIT_AUTH_IP_REQUESTS", "RATE_LIMIT_AUTH_USER_REQUESTS", "RATE_LIMIT_AUTH_WINDOW_SECONDS",
+		"RATE_LIMIT_ORGANIZATION_IP_REQUESTS", "RATE_LIMIT_ORGANIZATION_USER_REQUESTS", "RATE_LIMIT_ORGANIZATION_WINDOW_SECONDS",
+		"ADMIN_EMAILS", "IMPERSONATION_TOKEN_MINUTES", "OIDC_ISSUER", "OIDC_PRIVATE_KEY_PATH", "FEDERATED_PROVIDERS", "DOMAIN_VERIFIER",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigLoadsFilesInOrder(t *testing.T) {
+	clearConfigEnv(t)
+	app := writeConfigFile(t, "app.yaml", "server:\n  port: \"9000\"\ntoken:\n  secret: from-file\n  lifespan_hours: 2\n")
+	db := writeConfigFile(t, "db.yaml", "mongodb:\n  uri: mongodb://localhost:27017\n  database: orgs\n")
+
+	cfg, err := config.Load(app, db, filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "9000", cfg.Server.Port)
+	assert.Equal(t, "orgs", cfg.Mongo.Database)
+	assert.Equal(t, "from-file", cfg.Token.Secret)
+	assert.Equal(t, 2, cfg.Token.LifespanHours)
+	assert.Equal(t, "localhost:6379", cfg.Redis.Addr, "defaults fill in what the files leave out")
+	assert.Equal(t, "organization_management", cfg.Token.Issuer)
+}
+
+func TestConfigEnvironmentOverridesFiles(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeConfigFile(t, "app.yaml", "server:\n  port: \"9000\"\nmongodb:\n  uri: mongodb://file\n  database: orgs\ntoken:\n  secret: from-file\n")
+	t.Setenv("PORT", "9100")
+	t.Setenv("API_SECRET", "from-env")
+	t.Setenv("REDIS_DB", "3")
+	t.Setenv("SERVER_SHUTDOWN_TIMEOUT_SECONDS", "5")
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "9100", cfg.Server.Port)
+	assert.Equal(t, "from-env", cfg.Token.Secret)
+	assert.Equal(t, 3, cfg.Redis.DB)
+	assert.Equal(t, 5, cfg.Server.ShutdownTimeoutSeconds)
+	assert.Equal(t, 75, cfg.Server.WriteTimeoutSeconds)
+	assert.Equal(t, "mongodb://file", cfg.Mongo.URI)
+}
+
+func TestConfigReportsEveryProblem(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("PORT", "not-a-port")
+	t.Setenv("TOKEN_HOUR_LIFESPAN", "soon")
+	t.Setenv("SERVER_IDLE_TIMEOUT_SECONDS", "-1")
+
+	_, err := config.Load()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "TOKEN_HOUR_LIFESPAN must be an integer")
+		assert.Contains(t, err.Error(), "server.port (PORT)")
+		assert.Contains(t, err.Error(), "server.idle_timeout_seconds (SERVER_IDLE_TIMEOUT_SECONDS) must be positive")
+		assert.Contains(t, err.Error(), "mongodb.uri (MONGOURI) is required")
+		assert.Contains(t, err.Error(), "mongodb.database (MONGODB_DATABASE_NAME) is required")
+		assert.Contains(t, err.Error(), "token.secret (API_SECRET) is required")
+	}
+}
+
+func TestConfigKeepsMetricsOffTheAPIPort(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("MONGOURI", "mongodb://localhost:27017")
+	t.Setenv("MONGODB_DATABASE_NAME", "orgs")
+	t.Setenv("API_SECRET", "secret")
+
+	cfg, err := config.Load()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "9090", cfg.Server.MetricsPort)
+	}
+
+	t.Setenv("METRICS_PORT", "8080")
+	_, err = config.Load()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "server.metrics_port (METRICS_PORT) must differ from server.port (PORT)")
+	}
+}
+
+func TestConfigKeepsPasswordHashingInRange(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("MONGOURI", "mongodb://localhost:27017")
+	t.Setenv("MONGODB_DATABASE_NAME", "orgs")
+	t.Setenv("API_SECRET", "secret")
+
+	cfg, err := config.Load()
+	if assert.NoError(t, err) {
+		assert.Equal(t, config.PasswordHashingConfig{
+			Algorithm: "argon2id", Argon2MemoryKiB: 19456, Argon2Iterations: 2, Argon2Parallelism: 1, BcryptCost: 12,
+		}, cfg.PasswordHashing)
+	}
+
+	// 256 lanes would wrap around to 0 in argon2's uint8
+	t.Setenv("PASSWORD_ARGON2_PARALLELISM", "256")
+	t.Setenv("PASSWORD_ARGON2_ITERATIONS", "0")
+	t.Setenv("PASSWORD_BCRYPT_COST", "3")
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "md5")
+	_, err = config.Load()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "password_hashing.argon2_parallelism (PASSWORD_ARGON2_PARALLELISM) must be between 1 and 255, got 256")
+		assert.Contains(t, err.Error(), "password_hashing.argon2_iterations (PASSWORD_ARGON2_ITERATIONS) must be between 1")
+		assert.Contains(t, err.Error(), "password_hashing.bcrypt_cost (PASSWORD_BCRYPT_COST) must be between 4 and 31, got 3")
+		assert.Contains(t, err.Error(), "password_hashing.algorithm (PASSWORD_HASH_ALGORITHM) must be argon2id or bcrypt")
+	}
+
+	t.Setenv("PASSWORD_ARGON2_PARALLELISM", "4")
+	t.Setenv("PASSWORD_ARGON2_MEMORY_KIB", "16")
+	_, err = config.Load()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "password_hashing.argon2_memory_kib (PASSWORD_ARGON2_MEMORY_KIB) must be between 32 and")
+	}
+}
+
+func TestConfigLoadsFeaturePolicies(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeConfigFile(t, "app.yaml", `mongodb:
+  uri: mongodb://localhost:27017
+  database: orgs
+token:
+  secret: secret
+admin:
+  emails: [" Ops@Example.com "]
+federation:
+  providers:
+    Okta:
+      issuer: https://okta.example.com
+      client_id: orgs
+      redirect_url: https://orgs.example.com/api/federation/okta/callback
+`)
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, config.LoginConfig{MaxAccountAttempts: 5, MaxIPAttempts: 20, AttemptWindowMinutes: 15, LockoutMinutes: 15}, cfg.Login)
+	assert.Equal(t, 8, cfg.PasswordPolicy.MinLength)
+	assert.Equal(t, 72, cfg.PasswordPolicy.MaxLength)
+	assert.True(t, cfg.PasswordPolicy.DisallowUserInfo)
+	assert.Equal(t, []string{"ops@example.com"}, cfg.Admin.Emails)
+	assert.Equal(t, 15, cfg.Admin.ImpersonationTokenMinutes)
+	assert.Equal(t, "http://localhost:8080", cfg.OIDC.Issuer)
+	assert.Equal(t, config.DomainVerifierDNS, cfg.Domains.Verifier)
+	if assert.Contains(t, cfg.Federation.Providers, "okta", "provider names are lowercased") {
+		assert.Equal(t, []string{"openid", "email", "profile"}, cfg.Federation.Providers["okta"].Scopes)
+	}
+
+	t.Setenv("PASSWORD_REQUIRE_DIGIT", "true")
+	t.Setenv("ADMIN_EMAILS", "root@example.com, ops@example.com,")
+	t.Setenv("OIDC_ISSUER", "https://orgs.example.com/")
+	t.Setenv("FEDERATED_PROVIDERS", "okta, entra")
+	t.Setenv("FEDERATED_OKTA_CLIENT_ID", "orgs-from-env")
+	t.Setenv("FEDERATED_ENTRA_ISSUER", "https://login.example.com/v2.0")
+	t.Setenv("FEDERATED_ENTRA_CLIENT_ID", "entra-client")
+	t.Setenv("FEDERATED_ENTRA_REDIRECT_URL", "https://orgs.example.com/api/federation/entra/callback")
+	t.Setenv("FEDERATED_ENTRA_SCOPES", "openid email")
+	t.Setenv("DOMAIN_VERIFIER", "Stub")
+
+	cfg, err = config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, cfg.PasswordPolicy.RequireDigit)
+	assert.Equal(t, []string{"root@example.com", "ops@example.com"}, cfg.Admin.Emails)
+	assert.Equal(t, "https://orgs.example.com", cfg.OIDC.Issuer)
+	assert.Equal(t, config.DomainVerifierStub, cfg.Domains.Verifier)
+	assert.Equal(t, config.FederatedProviderConfig{
+		Issuer:      "https://okta.example.com",
+		ClientID:    "orgs-from-env",
+		RedirectURL: "https://orgs.example.com/api/federation/okta/callback",
+		Scopes:      []string{"openid", "email", "profile"},
+	}, cfg.Federation.Providers["okta"], "the environment overrides what the file says about a provider")
+	assert.Equal(t, []string{"openid", "email"}, cfg.Federation.Providers["entra"].Scopes)
+}
+
+func TestConfigRejectsInvalidFeaturePolicies(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("MONGOURI", "mongodb://localhost:27017")
+	t.Setenv("MONGODB_DATABASE_NAME", "orgs")
+	t.Setenv("API_SECRET", "secret")
+	t.Setenv("PASSWORD_MAX_LENGTH", "200")
+	t.Setenv("PASSWORD_REQUIRE_SYMBOL", "sometimes")
+	t.Setenv("LOGIN_LOCKOUT_MINUTES", "0")
+	t.Setenv("RATE_LIMIT_AUTH_IP_REQUESTS", "-1")
+	t.Setenv("IMPERSONATION_TOKEN_MINUTES", "-5")
+	t.Setenv("OIDC_ISSUER", "orgs.example.com")
+	t.Setenv("FEDERATED_PROVIDERS", "okta")
+	t.Setenv("FEDERATED_OKTA_ISSUER", "https://okta.example.com")
+	t.Setenv("DOMAIN_VERIFIER", "none")
+
+	_, err := config.Load()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "password_policy.max_length (PASSWORD_MAX_LENGTH) must be between password_policy.min_length and 72, got 200")
+		assert.Contains(t, err.Error(), "PASSWORD_REQUIRE_SYMBOL must be true or false")
+		assert.Contains(t, err.Error(), "login.lockout_minutes (LOGIN_LOCKOUT_MINUTES) must be positive")
+		assert.Contains(t, err.Error(), "rate_limits.auth.ip_requests (RATE_LIMIT_AUTH_IP_REQUESTS) must not be negative")
+		assert.Contains(t, err.Error(), "admin.impersonation_token_minutes (IMPERSONATION_TOKEN_MINUTES) must be positive")
+		assert.Contains(t, err.Error(), "oidc.issuer (OIDC_ISSUER) must be an http or https URL")
+		assert.Contains(t, err.Error(), "federation.providers.okta.client_id (FEDERATED_OKTA_CLIENT_ID) is required")
+		assert.Contains(t, err.Error(), "federation.providers.okta.redirect_url (FEDERATED_OKTA_REDIRECT_URL) must be an http or https URL")
+		assert.Contains(t, err.Error(), "domains.verifier (DOMAIN_VERIFIER) must be dns or stub")
+	}
+}
+
+func TestConfigRejectsMalformedFile(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeConfigFile(t, "app.yaml", "server: [unclosed\n")
+
+	_, err := config.Load(path)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), path)
+	}
+}
@@ -0,0 +1,47 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	util "organization_management/pkg/utils"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	domain, err := util.NormalizeDomain(" Example.COM. ")
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", domain)
+
+	for _, invalid := range []string{"", "localhost", "example..com", "-bad.com", "user@example.com", "exa mple.com"} {
+		_, err := util.NormalizeDomain(invalid)
+		assert.ErrorIs(t, err, util.ErrInvalidDomain, invalid)
+	}
+}
+
+func TestEmailDomain(t *testing.T) {
+	assert.Equal(t, "example.com", util.EmailDomain("Jane@Example.com"))
+	assert.Equal(t, "", util.EmailDomain("not-an-email"))
+}
+
+func TestDomainChallenge(t *testing.T) {
+	assert.Equal(t, "_orgmanagement-challenge.example.com", util.DomainChallengeRecord("example.com"))
+	assert.Equal(t, "orgmanagement-verification=abc", util.DomainChallengeValue("abc"))
+}
+
+func TestNewDomainVerifier(t *testing.T) {
+	stub, err := util.NewDomainVerifier(util.DomainVerifierStub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verified, err := stub.Verify(context.Background(), "example.com", "token")
+	assert.NoError(t, err)
+	assert.True(t, verified)
+
+	dns, err := util.NewDomainVerifier(util.DomainVerifierDNS)
+	assert.NoError(t, err)
+	assert.IsType(t, util.DNSDomainVerifier{}, dns)
+
+	_, err = util.NewDomainVerifier("none")
+	assert.Error(t, err)
+}
@@ -0,0 +1,159 @@
+package unit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	util "organization_management/pkg/utils"
+)
+
+// newMockIdentityProvider serves the discovery document, keys and token endpoint of an OpenID Connect
+// provider that signs in a single user with the given nonce.
+func newMockIdentityProvider(t *testing.T, nonce string) *httptest.Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	signer := util.NewOIDCSigner(key, server.URL)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                server.URL,
+			"authorization_endpoint":                server.URL + "/authorize",
+			"token_endpoint":                        server.URL + "/token",
+			"jwks_uri":                              server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(signer.JWKS())
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("code") != "valid-code" || r.PostForm.Get("code_verifier") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		idToken, err := signer.SignIDToken("corp-user-1", "our-client", time.Hour, jwt.MapClaims{
+			"nonce":          nonce,
+			"email":          "Jane@Corp.example",
+			"email_verified": true,
+			"name":           "Jane Doe",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "upstream-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	return server
+}
+
+func newTestFederatedProvider(t *testing.T, issuer string) *util.FederatedProvider {
+	provider, err := util.NewFederatedProvider(context.Background(), util.FederatedProviderConfig{
+		Name:         "corp",
+		IssuerURL:    issuer,
+		ClientID:     "our-client",
+		ClientSecret: "our-secret",
+		RedirectURL:  "http://localhost:8080/api/auth/corp/callback",
+		Scopes:       []string{"openid", "email", "profile"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestFederatedAuthCodeURLUsesPKCE(t *testing.T) {
+	server := newMockIdentityProvider(t, "nonce-1")
+	provider := newTestFederatedProvider(t, server.URL)
+
+	state, nonce, verifier, err := util.GenerateFederationState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := url.Parse(provider.AuthCodeURL(state, nonce, verifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := authURL.Query()
+	assert.Equal(t, server.URL+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	assert.Equal(t, state, query.Get("state"))
+	assert.Equal(t, nonce, query.Get("nonce"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.True(t, util.VerifyPKCE(verifier, query.Get("code_challenge"), "S256"))
+}
+
+func TestFederatedExchangeReturnsVerifiedIdentity(t *testing.T) {
+	server := newMockIdentityProvider(t, "nonce-1")
+	provider := newTestFederatedProvider(t, server.URL)
+
+	identity, err := provider.Exchange(context.Background(), "valid-code", "verifier", "nonce-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "corp", identity.Provider)
+	assert.Equal(t, "corp-user-1", identity.Subject)
+	assert.Equal(t, "jane@corp.example", identity.Email)
+	assert.True(t, identity.EmailVerified)
+	assert.Equal(t, "Jane Doe", identity.Name)
+}
+
+func TestFederatedExchangeRejectsWrongNonce(t *testing.T) {
+	server := newMockIdentityProvider(t, "nonce-1")
+	provider := newTestFederatedProvider(t, server.URL)
+
+	_, err := provider.Exchange(context.Background(), "valid-code", "verifier", "another-nonce")
+	assert.Error(t, err)
+}
+
+func TestFederatedProvidersDiscoverWithoutBlockingOtherProviders(t *testing.T) {
+	fast := newMockIdentityProvider(t, "nonce-1")
+
+	discovering := make(chan struct{})
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(discovering)
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(slow.Close)
+
+	providers := util.NewFederatedProviders(map[string]util.FederatedProviderConfig{
+		"slowcorp": {Name: "slowcorp", IssuerURL: slow.URL},
+		"fastcorp": {Name: "fastcorp", IssuerURL: fast.URL, ClientID: "our-client"},
+	})
+
+	slowDone := make(chan error, 1)
+	go func() {
+		_, err := providers.Get(context.Background(), "slowcorp")
+		slowDone <- err
+	}()
+	<-discovering
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	provider, err := providers.Get(ctx, "fastcorp")
+	assert.NoError(t, err)
+	assert.NotNil(t, provider)
+
+	close(release)
+	assert.Error(t, <-slowDone, "a failed discovery is reported")
+}
@@ -0,0 +1,79 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"organization_management/pkg"
+	"organization_management/pkg/config"
+	controller "organization_management/pkg/controllers"
+)
+
+type readinessResponse struct {
+	Status       string                                 `json:"status"`
+	ShuttingDown bool                                   `json:"shutting_down"`
+	Dependencies map[string]controller.DependencyStatus `json:"dependencies"`
+}
+
+func probeReadiness(t *testing.T, accepting bool, checks ...controller.DependencyCheck) (int, readinessResponse) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/readyz", controller.Readiness(func() bool { return accepting }, checks...))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var body readinessResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, body
+}
+
+func healthyCheck(name string) controller.DependencyCheck {
+	return controller.DependencyCheck{Name: name, Ping: func(context.Context) error { return nil }}
+}
+
+func TestReadinessReportsEachDependency(t *testing.T) {
+	code, body := probeReadiness(t, true, healthyCheck("mongodb"), healthyCheck("redis"))
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", body.Status)
+	assert.False(t, body.ShuttingDown)
+	assert.Equal(t, "up", body.Dependencies["mongodb"].Status)
+	assert.Equal(t, "up", body.Dependencies["redis"].Status)
+}
+
+func TestReadinessFailsWhenADependencyIsDown(t *testing.T) {
+	down := controller.DependencyCheck{Name: "redis", Ping: func(context.Context) error {
+		return errors.New("connection refused")
+	}}
+	code, body := probeReadiness(t, true, healthyCheck("mongodb"), down)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not_ready", body.Status)
+	assert.Equal(t, "up", body.Dependencies["mongodb"].Status)
+	assert.Equal(t, "down", body.Dependencies["redis"].Status)
+	assert.Equal(t, "connection refused", body.Dependencies["redis"].Error)
+}
+
+func TestReadinessFailsDuringShutdown(t *testing.T) {
+	code, body := probeReadiness(t, false, healthyCheck("mongodb"))
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.True(t, body.ShuttingDown)
+}
+
+func TestLivenessChecksNothing(t *testing.T) {
+	router := (&pkg.App{Config: config.Default()}).Router()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
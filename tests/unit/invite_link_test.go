@@ -0,0 +1,37 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	model "organization_management/pkg/database/mongodb/models"
+	util "organization_management/pkg/utils"
+)
+
+func TestGeneratedInviteTokenHashesConsistently(t *testing.T) {
+	token, hashedToken, err := util.GenerateInviteToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, strings.HasPrefix(token, util.InviteTokenPrefix))
+	assert.Equal(t, hashedToken, util.HashInviteToken(token))
+	assert.NotEqual(t, hashedToken, util.HashInviteToken(token+"x"))
+
+	other, _, err := util.GenerateInviteToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestInviteLinkIsActive(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+
+	assert.True(t, (&model.InviteLink{ExpiresAt: now.Add(time.Hour)}).IsActive(now), "no use limit")
+	assert.True(t, (&model.InviteLink{ExpiresAt: now.Add(time.Hour), MaxUses: 2, Uses: 1}).IsActive(now))
+	assert.False(t, (&model.InviteLink{ExpiresAt: now.Add(time.Hour), MaxUses: 2, Uses: 2}).IsActive(now), "used up")
+	assert.False(t, (&model.InviteLink{ExpiresAt: now.Add(-time.Second)}).IsActive(now), "expired")
+	assert.False(t, (&model.InviteLink{ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}).IsActive(now), "revoked")
+}
@@ -0,0 +1,19 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	util "organization_management/pkg/utils"
+)
+
+func TestLoginDelayGrowsWithFailures(t *testing.T) {
+	policy := util.LoginPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	assert.Equal(t, time.Duration(0), policy.DelayAfter(1))
+	assert.Equal(t, time.Second, policy.DelayAfter(2))
+	assert.Equal(t, 2*time.Second, policy.DelayAfter(3))
+	assert.Equal(t, 4*time.Second, policy.DelayAfter(4))
+	assert.Equal(t, 5*time.Second, policy.DelayAfter(10))
+}
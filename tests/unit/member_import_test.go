@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	util "organization_management/pkg/utils"
+)
+
+func TestParseMemberImportCSV(t *testing.T) {
+	file := "name,Email,access_level\n" +
+		"Jane,jane@example.com,admin\n" +
+		"\n" +
+		"John, john@example.com ,\n" +
+		"Bad,\"unterminated,member\n"
+
+	rows, rowErrors, err := util.ParseMemberImport(strings.NewReader(file), util.MemberImportCSV)
+	assert.NoError(t, err)
+	assert.Equal(t, []util.MemberImportRow{
+		{Line: 2, Email: "jane@example.com", AccessLevel: "admin"},
+		{Line: 4, Email: "john@example.com", AccessLevel: ""},
+	}, rows)
+	assert.Len(t, rowErrors, 1)
+	assert.Equal(t, 5, rowErrors[0].Line)
+}
+
+func TestParseMemberImportCSVRequiresEmailColumn(t *testing.T) {
+	_, _, err := util.ParseMemberImport(strings.NewReader("name,access_level\nJane,admin\n"), util.MemberImportCSV)
+	assert.Error(t, err)
+}
+
+func TestParseMemberImportJSONL(t *testing.T) {
+	file := `{"email":"jane@example.com","access_level":"member"}` + "\n" +
+		"\n" +
+		"not json\n" +
+		`{"email":"john@example.com"}` + "\n"
+
+	rows, rowErrors, err := util.ParseMemberImport(strings.NewReader(file), util.MemberImportJSONL)
+	assert.NoError(t, err)
+	assert.Equal(t, []util.MemberImportRow{
+		{Line: 1, Email: "jane@example.com", AccessLevel: "member"},
+		{Line: 4, Email: "john@example.com"},
+	}, rows)
+	assert.Len(t, rowErrors, 1)
+	assert.Equal(t, 3, rowErrors[0].Line)
+}
+
+func TestMemberImportFormat(t *testing.T) {
+	format, err := util.MemberImportFormat("", "text/csv; charset=utf-8")
+	assert.NoError(t, err)
+	assert.Equal(t, util.MemberImportCSV, format)
+
+	format, err = util.MemberImportFormat("JSONL", "text/csv")
+	assert.NoError(t, err)
+	assert.Equal(t, util.MemberImportJSONL, format)
+
+	_, err = util.MemberImportFormat("", "application/json")
+	assert.ErrorIs(t, err, util.ErrUnsupportedImportFormat)
+}
+
+func TestCSVSafe(t *testing.T) {
+	assert.Equal(t, "'=HYPERLINK(\"x\")", util.CSVSafe("=HYPERLINK(\"x\")"))
+	assert.Equal(t, "'@cmd", util.CSVSafe("@cmd"))
+	assert.Equal(t, "jane@example.com", util.CSVSafe("jane@example.com"))
+}
@@ -0,0 +1,77 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"organization_management/pkg/metrics"
+)
+
+func scrapeMetrics(t *testing.T) string {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	metrics.Handler()(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	return w.Body.String()
+}
+
+func TestRequestMetricsUseTheRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(metrics.Middleware())
+	router.GET("/things/:thing_id", func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/things/42", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nowhere", nil))
+
+	body := scrapeMetrics(t)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/things/:thing_id",status="202"} 1`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, body, `route="/things/42"`)
+}
+
+func TestBusinessCountersAreExposed(t *testing.T) {
+	metrics.RecordLogin(metrics.LoginMethodPassword, false)
+	metrics.RecordInviteSent(metrics.InviteKindLink)
+
+	body := scrapeMetrics(t)
+	assert.Contains(t, body, `auth_logins_total{method="password",result="failed"}`)
+	assert.Contains(t, body, `organization_invites_sent_total{kind="link"}`)
+}
+
+func TestMongoMonitorMatchesCommandsByConnectionAndRequest(t *testing.T) {
+	monitor := metrics.MongoMonitor()
+	started := func(connectionID, collection string) {
+		monitor.Started(context.Background(), &event.CommandStartedEvent{
+			Command:      bson.Raw(bsoncore.NewDocumentBuilder().AppendString("find", collection).Build()),
+			CommandName:  "find",
+			RequestID:    7,
+			ConnectionID: connectionID,
+		})
+	}
+	succeeded := func(connectionID string) {
+		monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{
+			CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 7, ConnectionID: connectionID},
+		})
+	}
+
+	// Request ids are per connection, so two commands in flight can share one
+	started("conn-a", "monitor_users")
+	started("conn-b", "monitor_organizations")
+	succeeded("conn-a")
+	succeeded("conn-b")
+
+	body := scrapeMetrics(t)
+	assert.Contains(t, body, `mongodb_operation_duration_seconds_count{collection="monitor_users",command="find",outcome="ok"} 1`)
+	assert.Contains(t, body, `mongodb_operation_duration_seconds_count{collection="monitor_organizations",command="find",outcome="ok"} 1`)
+}
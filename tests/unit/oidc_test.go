@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	controller "organization_management/pkg/controllers"
+	util "organization_management/pkg/utils"
+)
+
+func TestVerifyPKCE(t *testing.T) {
+	// Example from RFC 7636, appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	assert.True(t, util.VerifyPKCE(verifier, challenge, "S256"))
+	assert.False(t, util.VerifyPKCE(verifier, challenge, "plain"))
+	assert.False(t, util.VerifyPKCE("wrong-verifier", challenge, "S256"))
+}
+
+func TestIDTokenVerifiesWithPublishedKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := util.NewOIDCSigner(key, "https://orgs.example.com/")
+
+	idToken, err := signer.SignIDToken("user-1", "client-1", time.Hour, jwt.MapClaims{"nonce": "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rebuild the public key from the JWKS document the way a relying party would
+	jwk := signer.JWKS()["keys"].([]map[string]string)[0]
+	n, _ := base64.RawURLEncoding.DecodeString(jwk["n"])
+	e, _ := base64.RawURLEncoding.DecodeString(jwk["e"])
+	publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		assert.Equal(t, jwk["kid"], token.Header["kid"])
+		return publicKey, nil
+	}, jwt.WithIssuer("https://orgs.example.com"), jwt.WithAudience("client-1"))
+	assert.NoError(t, err)
+	assert.True(t, token.Valid)
+	assert.Equal(t, "user-1", claims["sub"])
+	assert.Equal(t, "abc", claims["nonce"])
+}
+
+func TestDiscoveryUsesTheHandlerSigner(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := util.NewOIDCSigner(key, "https://orgs.example.com/")
+	h := controller.NewHandler(nil, nil, controller.Features{OIDC: signer})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/.well-known/openid-configuration", h.OIDCDiscovery())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/openid-configuration", nil))
+
+	var discovery map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &discovery); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://orgs.example.com", discovery["issuer"])
+	assert.Equal(t, "https://orgs.example.com/oauth2/jwks", discovery["jwks_uri"])
+}
+
+func TestCSRFTokenMatches(t *testing.T) {
+	token, err := util.GenerateCSRFToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, util.CSRFTokenMatches(token, token))
+	assert.False(t, util.CSRFTokenMatches("", ""), "a missing value never matches")
+	assert.False(t, util.CSRFTokenMatches(token, token+"x"))
+}
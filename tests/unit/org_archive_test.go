@@ -0,0 +1,37 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	util "organization_management/pkg/utils"
+)
+
+func TestDecodeOrganizationArchive(t *testing.T) {
+	archive, err := util.DecodeOrganizationArchive(strings.NewReader(`{
+		"version": 1,
+		"organization": {"organization_id": "abc", "name": "Acme", "members": [{"name": "Jane", "email": "jane@example.com", "access_level": "Founder"}]},
+		"service_accounts": [{"client_id": "sa_1", "hashed_secret": "h"}],
+		"audit_events": [{"event_id": "e1", "actor_email": "ops@example.com", "action": "organization.ownership_transferred", "details": {"founder": "jane@example.com"}}]
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Acme", archive.Organization.Name)
+	assert.Len(t, archive.Organization.Members, 1)
+	assert.Equal(t, "h", archive.ServiceAccounts[0].HashedSecret)
+	assert.Equal(t, "ops@example.com", archive.AuditEvents[0].ActorEmail)
+	assert.Equal(t, "jane@example.com", archive.AuditEvents[0].Details["founder"])
+}
+
+func TestDecodeOrganizationArchiveRejectsOtherVersions(t *testing.T) {
+	_, err := util.DecodeOrganizationArchive(strings.NewReader(`{"version": 2, "organization": {"name": "Acme"}}`))
+	assert.ErrorIs(t, err, util.ErrUnsupportedArchiveVersion)
+
+	_, err = util.DecodeOrganizationArchive(strings.NewReader(`{"version": 1, "organization": {}}`))
+	assert.Error(t, err)
+}
+
+func TestValidArchiveConflict(t *testing.T) {
+	assert.True(t, util.ValidArchiveConflict(util.ArchiveConflictRename))
+	assert.False(t, util.ValidArchiveConflict("overwrite"))
+}
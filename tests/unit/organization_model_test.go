@@ -0,0 +1,60 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	model "organization_management/pkg/database/mongodb/models"
+)
+
+func TestIsSoleFounder(t *testing.T) {
+	org := model.Organization{OrganizationMembers: []model.OrganizationMember{
+		{UserEmail: "founder@example.com", AccessLevel: model.AccessLevelFounder},
+		{UserEmail: "admin@example.com", AccessLevel: model.AccessLevelAdmin},
+	}}
+	assert.True(t, org.IsSoleFounder("Founder@example.com"))
+	assert.False(t, org.IsSoleFounder("admin@example.com"))
+
+	org.OrganizationMembers = append(org.OrganizationMembers, model.OrganizationMember{UserEmail: "other@example.com", AccessLevel: model.AccessLevelFounder})
+	assert.False(t, org.IsSoleFounder("founder@example.com"))
+}
+
+func TestSuccessorPrefersAdminsOverMembers(t *testing.T) {
+	org := model.Organization{OrganizationMembers: []model.OrganizationMember{
+		{UserEmail: "founder@example.com", AccessLevel: model.AccessLevelFounder},
+		{UserEmail: "sa_1@service-accounts.local", AccessLevel: model.AccessLevelAdmin, Type: model.MemberTypeServiceAccount},
+		{UserEmail: "member@example.com", AccessLevel: model.AccessLevelMember},
+		{UserEmail: "admin@example.com", AccessLevel: model.AccessLevelAdmin},
+	}}
+	assert.Equal(t, "admin@example.com", org.Successor("founder@example.com").UserEmail)
+
+	org.OrganizationMembers = org.OrganizationMembers[:3]
+	assert.Equal(t, "member@example.com", org.Successor("founder@example.com").UserEmail)
+
+	org.OrganizationMembers = org.OrganizationMembers[:2]
+	assert.Nil(t, org.Successor("founder@example.com"))
+}
+
+func TestUserAccountStatus(t *testing.T) {
+	user := model.User{}
+	assert.Equal(t, model.UserStatusActive, user.AccountStatus())
+	assert.True(t, user.IsActive())
+
+	for _, status := range []string{model.UserStatusSuspended, model.UserStatusDisabled} {
+		user.Status = status
+		assert.Equal(t, status, user.AccountStatus())
+		assert.False(t, user.IsActive())
+	}
+}
+
+func TestMemberStatusIsNeverStored(t *testing.T) {
+	member := model.OrganizationMember{UserEmail: "jane@example.com", AccessLevel: model.AccessLevelMember, Status: model.UserStatusSuspended}
+
+	stored, err := bson.Marshal(member)
+	assert.NoError(t, err)
+	_, err = bson.Raw(stored).LookupErr("status")
+	assert.Error(t, err)
+	_, err = bson.Raw(stored).LookupErr("useremail")
+	assert.NoError(t, err)
+}
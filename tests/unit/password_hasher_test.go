@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	util "organization_management/pkg/utils"
+)
+
+func testArgon2idHasher() *util.Argon2idHasher {
+	return &util.Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+}
+
+func TestArgon2idHashRoundTrip(t *testing.T) {
+	hasher := testArgon2idHasher()
+
+	hashed, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, strings.HasPrefix(hashed, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	assert.True(t, hasher.Handles(hashed))
+	assert.True(t, hasher.Current(hashed))
+	assert.NoError(t, hasher.Verify("correct horse", hashed))
+	assert.True(t, errors.Is(hasher.Verify("battery staple", hashed), util.ErrPasswordMismatch))
+
+	other, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, hashed, other)
+}
+
+func TestPasswordHashingVerifiesEveryAlgorithm(t *testing.T) {
+	bcryptHasher := &util.BcryptHasher{Cost: 4}
+	hashing := &util.PasswordHashing{
+		Preferred: testArgon2idHasher(),
+		Supported: []util.PasswordHasher{testArgon2idHasher(), bcryptHasher},
+	}
+
+	legacy, err := bcryptHasher.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, hashing.Verify("correct horse", legacy))
+	assert.True(t, errors.Is(hashing.Verify("battery staple", legacy), util.ErrPasswordMismatch))
+	assert.True(t, errors.Is(hashing.Verify("correct horse", "plaintext"), util.ErrUnknownPasswordHash))
+
+	current, err := hashing.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, hashing.Verify("correct horse", current))
+	assert.False(t, hashing.NeedsRehash(current))
+	assert.True(t, hashing.NeedsRehash(legacy))
+}
+
+func TestPasswordNeedsRehashWhenParametersChange(t *testing.T) {
+	weak := testArgon2idHasher()
+	hashed, err := weak.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stronger := testArgon2idHasher()
+	stronger.Iterations = 2
+	hashing := &util.PasswordHashing{Preferred: stronger, Supported: []util.PasswordHasher{stronger}}
+	assert.True(t, hashing.NeedsRehash(hashed))
+	assert.NoError(t, hashing.Verify("correct horse", hashed))
+
+	bcryptHashing := &util.PasswordHashing{Preferred: &util.BcryptHasher{Cost: 5}}
+	cheap, err := (&util.BcryptHasher{Cost: 4}).Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, bcryptHashing.NeedsRehash(cheap))
+}
+
+func TestNewPasswordHashing(t *testing.T) {
+	settings := util.PasswordHashingSettings{
+		Algorithm:         util.PasswordHashArgon2id,
+		Argon2MemoryKiB:   1024,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+		BcryptCost:        11,
+	}
+	hashing, err := util.NewPasswordHashing(settings)
+	assert.NoError(t, err)
+	assert.Equal(t, &util.Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}, hashing.Preferred)
+
+	settings.Algorithm = util.PasswordHashBcrypt
+	hashing, err = util.NewPasswordHashing(settings)
+	assert.NoError(t, err)
+	assert.Equal(t, &util.BcryptHasher{Cost: 11}, hashing.Preferred)
+
+	settings.Algorithm = "md5"
+	_, err = util.NewPasswordHashing(settings)
+	assert.True(t, errors.Is(err, util.ErrUnsupportedPasswordHasher))
+
+	// argon2 would panic on these rather than fail
+	settings.Algorithm = util.PasswordHashArgon2id
+	settings.Argon2Parallelism = 0
+	_, err = util.NewPasswordHashing(settings)
+	assert.Error(t, err)
+}
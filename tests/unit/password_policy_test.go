@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	util "organization_management/pkg/utils"
+)
+
+func violatedRules(t *testing.T, policy util.PasswordPolicy, password, email, name string) []string {
+	violations, err := policy.Check(password, email, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := []string{}
+	for _, violation := range violations {
+		rules = append(rules, violation.Rule)
+	}
+	return rules
+}
+
+func TestPasswordPolicyReportsEveryBrokenRule(t *testing.T) {
+	policy := util.PasswordPolicy{
+		MinLength:        10,
+		MaxLength:        72,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	}
+
+	assert.Equal(t,
+		[]string{util.PasswordRuleMinLength, util.PasswordRuleUppercase, util.PasswordRuleDigit, util.PasswordRuleSymbol},
+		violatedRules(t, policy, "short", "jane@example.com", "Jane Doe"))
+	assert.Empty(t, violatedRules(t, policy, "Correct-Horse-7", "jane@example.com", "Jane Doe"))
+	assert.Equal(t, []string{util.PasswordRuleMaxLength},
+		violatedRules(t, policy, "Aa1!"+strings.Repeat("x", 80), "jane@example.com", "Jane Doe"))
+}
+
+func TestPasswordPolicyRejectsUserInfo(t *testing.T) {
+	policy := util.PasswordPolicy{MinLength: 1, DisallowUserInfo: true}
+
+	assert.Equal(t, []string{util.PasswordRuleContainsUser}, violatedRules(t, policy, "JaneSmith2024", "jsmith@example.com", "Jane Smith"))
+	assert.Equal(t, []string{util.PasswordRuleContainsUser}, violatedRules(t, policy, "hello-jsmith", "jsmith@example.com", "J S"))
+	assert.Empty(t, violatedRules(t, policy, "al-is-short-enough", "x@example.com", "Al"))
+
+	policy.DisallowUserInfo = false
+	assert.Empty(t, violatedRules(t, policy, "JaneSmith2024", "jsmith@example.com", "Jane Smith"))
+}
+
+func TestBreachedPasswordRanges(t *testing.T) {
+	dir := t.TempDir()
+	sum := sha1.Sum([]byte("password123"))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	content := "0000000000000000000000000000000000A:3\n" + strings.ToLower(hash[5:]) + ":2\n"
+	if err := os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ranges := &util.BreachedPasswordRanges{Dir: dir, MinCount: 1}
+	breached, err := ranges.IsBreached("password123")
+	assert.NoError(t, err)
+	assert.True(t, breached)
+
+	breached, err = ranges.IsBreached("a password nobody has used")
+	assert.NoError(t, err)
+	assert.False(t, breached)
+
+	ranges.MinCount = 5
+	breached, err = ranges.IsBreached("password123")
+	assert.NoError(t, err)
+	assert.False(t, breached)
+
+	policy := util.PasswordPolicy{MinLength: 1, Breached: &util.BreachedPasswordRanges{Dir: dir, MinCount: 1}}
+	assert.Equal(t, []string{util.PasswordRuleBreached}, violatedRules(t, policy, "password123", "", ""))
+}
+
+func TestNewPasswordPolicyRefusesLengthsBcryptCannotHash(t *testing.T) {
+	_, err := util.NewPasswordPolicy(util.PasswordPolicySettings{MinLength: 8, MaxLength: 200})
+	assert.Error(t, err)
+	_, err = util.NewPasswordPolicy(util.PasswordPolicySettings{MinLength: 0, MaxLength: 72})
+	assert.Error(t, err)
+
+	policy, err := util.NewPasswordPolicy(util.PasswordPolicySettings{MinLength: 8, MaxLength: 72, DisallowUserInfo: true})
+	assert.NoError(t, err)
+	assert.True(t, policy.DisallowUserInfo)
+	assert.Nil(t, policy.Breached)
+
+	policy, err = util.NewPasswordPolicy(util.PasswordPolicySettings{MinLength: 8, MaxLength: 72, BreachedPasswordsDir: t.TempDir(), BreachedPasswordMinCount: 2})
+	assert.NoError(t, err)
+	assert.NotNil(t, policy.Breached)
+}
@@ -0,0 +1,40 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"organization_management/pkg/config"
+	util "organization_management/pkg/utils"
+)
+
+func TestRateLimitDefaults(t *testing.T) {
+	limits := config.Default().RateLimits
+	assert.Equal(t, config.RateLimitConfig{IPRequests: 30, WindowSeconds: 60}, limits.Auth, "auth routes aren't limited per user")
+	assert.Equal(t, config.RateLimitConfig{IPRequests: 300, UserRequests: 120, WindowSeconds: 60}, limits.Organization)
+}
+
+func TestRateLimitOverrides(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("MONGOURI", "mongodb://localhost:27017")
+	t.Setenv("MONGODB_DATABASE_NAME", "orgs")
+	t.Setenv("API_SECRET", "secret")
+	t.Setenv("RATE_LIMIT_ORGANIZATION_IP_REQUESTS", "50")
+	t.Setenv("RATE_LIMIT_ORGANIZATION_USER_REQUESTS", "0")
+	t.Setenv("RATE_LIMIT_ORGANIZATION_WINDOW_SECONDS", "30")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, config.RateLimitConfig{IPRequests: 50, UserRequests: 0, WindowSeconds: 30}, cfg.RateLimits.Organization)
+	assert.Equal(t, 30, cfg.RateLimits.Auth.IPRequests)
+}
+
+func TestNewRateLimitPolicy(t *testing.T) {
+	policy := util.NewRateLimitPolicy("organization", 50, 0, 30*time.Second)
+	assert.Equal(t, "organization", policy.Name)
+	assert.Equal(t, util.RateLimit{Requests: 50, Window: 30 * time.Second}, policy.PerIP)
+	assert.Equal(t, int64(0), policy.PerUser.Requests, "zero turns the per-user limit off")
+}
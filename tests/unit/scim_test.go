@@ -0,0 +1,41 @@
+package unit
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	util "organization_management/pkg/utils"
+)
+
+func TestParseSCIMFilter(t *testing.T) {
+	filter, err := util.ParseSCIMFilter(`userName eq "Jane.Doe@example.com"`)
+	assert.NoError(t, err)
+	assert.Equal(t, "userName", filter.Attribute)
+	assert.Equal(t, "eq", filter.Operator)
+
+	pattern := regexp.MustCompile(filter.Pattern())
+	assert.True(t, pattern.MatchString("jane.doe@example.com"))
+	assert.False(t, pattern.MatchString("janexdoe@example.com"))
+	assert.False(t, pattern.MatchString("jane.doe@example.com.evil"))
+
+	filter, err = util.ParseSCIMFilter(`displayName sw "Eng"`)
+	assert.NoError(t, err)
+	assert.True(t, regexp.MustCompile(filter.Pattern()).MatchString("Engineering"))
+
+	filter, err = util.ParseSCIMFilter("")
+	assert.NoError(t, err)
+	assert.Nil(t, filter)
+
+	_, err = util.ParseSCIMFilter(`userName eq "a" and active eq true`)
+	assert.ErrorIs(t, err, util.ErrInvalidSCIMFilter)
+}
+
+func TestParseSCIMMemberPath(t *testing.T) {
+	id, ok := util.ParseSCIMMemberPath(`members[value eq "65f1c0ffee"]`)
+	assert.True(t, ok)
+	assert.Equal(t, "65f1c0ffee", id)
+
+	_, ok = util.ParseSCIMMemberPath("members")
+	assert.False(t, ok)
+}
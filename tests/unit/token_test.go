@@ -0,0 +1,173 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	util "organization_management/pkg/utils"
+)
+
+var testTokenSettings = util.TokenSettings{
+	Secret:   "test-secret",
+	Lifespan: time.Hour,
+	Leeway:   5 * time.Second,
+	Issuer:   "test-issuer",
+	Audience: "test-audience",
+}
+
+func newTestVerifier(t *testing.T) *util.TokenVerifier {
+	verifier, err := util.NewTokenVerifierFromSettings(testTokenSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return verifier
+}
+
+func TestNewTokenVerifierRefusesIncompleteSettings(t *testing.T) {
+	_, err := util.NewTokenVerifierFromSettings(util.TokenSettings{Lifespan: time.Hour})
+	assert.Error(t, err, "a secret is required")
+	_, err = util.NewTokenVerifierFromSettings(util.TokenSettings{Secret: "secret"})
+	assert.Error(t, err, "a lifespan is required")
+}
+
+func TestAccessTokenIsAccepted(t *testing.T) {
+	verifier := newTestVerifier(t)
+
+	token, err := verifier.Sign("user-1", "john@example.com", util.TokenTypeAccess, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := verifier.Verify(token, util.TokenTypeAccess)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID())
+	assert.Equal(t, "john@example.com", claims.Email)
+}
+
+func TestIssueTimeKeepsSubSecondPrecision(t *testing.T) {
+	verifier := newTestVerifier(t)
+
+	before := time.Now().Truncate(time.Microsecond)
+	token, err := verifier.Sign("user-1", "john@example.com", util.TokenTypeAccess, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := verifier.Verify(token, util.TokenTypeAccess)
+	assert.NoError(t, err)
+	assert.False(t, claims.IssueTime().Before(before), "iat alone would round down to the second")
+	assert.Equal(t, claims.IssuedAt.Unix(), claims.IssueTime().Unix())
+
+	legacy := util.TokenClaims{}
+	legacy.IssuedAt = claims.IssuedAt
+	assert.Equal(t, claims.IssuedAt.Time, legacy.IssueTime(), "tokens without iat_us fall back to iat")
+}
+
+func TestRefreshTokenIsRejectedAsAccessToken(t *testing.T) {
+	verifier := newTestVerifier(t)
+
+	token, err := verifier.Sign("user-1", "john@example.com", util.TokenTypeRefresh, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = verifier.Verify(token, util.TokenTypeAccess)
+	assert.True(t, errors.Is(err, util.ErrWrongTokenType))
+}
+
+func TestTokenForAnotherAudienceIsRejected(t *testing.T) {
+	verifier := newTestVerifier(t)
+	token, err := verifier.Sign("user-1", "john@example.com", util.TokenTypeAccess, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := testTokenSettings
+	settings.Audience = "another-audience"
+	otherVerifier, err := util.NewTokenVerifierFromSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = otherVerifier.Verify(token, util.TokenTypeAccess)
+	assert.True(t, errors.Is(err, util.ErrInvalidToken))
+}
+
+func TestExpiredTokenIsRejectedOutsideLeeway(t *testing.T) {
+	verifier := newTestVerifier(t)
+
+	withinLeeway, err := verifier.Sign("user-1", "john@example.com", util.TokenTypeAccess, -2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = verifier.Verify(withinLeeway, util.TokenTypeAccess)
+	assert.NoError(t, err)
+
+	expired, err := verifier.Sign("user-1", "john@example.com", util.TokenTypeAccess, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = verifier.Verify(expired, util.TokenTypeAccess)
+	assert.True(t, errors.Is(err, util.ErrInvalidToken))
+}
+
+func TestImpersonationClaimSurvivesVerification(t *testing.T) {
+	verifier := newTestVerifier(t)
+
+	claims := util.TokenClaims{
+		Email:     "john@example.com",
+		TokenType: util.TokenTypeAccess,
+		Actor:     &util.TokenActor{Subject: "admin-1", Email: "ops@example.com"},
+	}
+	token, err := verifier.SignClaims(claims, "user-1", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verified, err := verifier.Verify(token, util.TokenTypeAccess)
+	assert.NoError(t, err)
+	assert.True(t, verified.IsImpersonated())
+	assert.Equal(t, "user-1", verified.UserID())
+	assert.Equal(t, "admin-1", verified.Actor.Subject)
+	assert.Equal(t, "ops@example.com", verified.Actor.Email)
+
+	plain, err := verifier.Sign("user-1", "john@example.com", util.TokenTypeAccess, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verified, err = verifier.Verify(plain, util.TokenTypeAccess)
+	assert.NoError(t, err)
+	assert.False(t, verified.IsImpersonated())
+}
+
+func TestClientAccessTokenIsBoundToTheClient(t *testing.T) {
+	verifier := newTestVerifier(t)
+
+	token, err := verifier.SignClientAccessToken("user-1", "john@example.com", "oidc_client", []string{"openid", "email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = verifier.Verify(token, util.TokenTypeAccess, util.TokenTypeServiceAccount)
+	assert.True(t, errors.Is(err, util.ErrInvalidToken), "not accepted by the API")
+
+	claims, err := verifier.VerifyClientAccessToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID())
+	assert.Equal(t, []string{"openid", "email"}, claims.Scopes)
+	assert.Equal(t, "oidc_client", claims.Audience[0])
+}
+
+func TestAccessTokenIsNotAClientAccessToken(t *testing.T) {
+	verifier := newTestVerifier(t)
+
+	token, err := verifier.Sign("user-1", "john@example.com", util.TokenTypeAccess, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = verifier.VerifyClientAccessToken(token)
+	assert.Error(t, err)
+}
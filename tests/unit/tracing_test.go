@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"organization_management/pkg"
+	"organization_management/pkg/config"
+	"organization_management/pkg/tracing"
+)
+
+func TestTracingIsOffByDefault(t *testing.T) {
+	shutdown, err := tracing.Setup(context.Background(), config.Default().Tracing)
+	if assert.NoError(t, err) {
+		assert.NoError(t, shutdown(context.Background()))
+	}
+	assert.Contains(t, otel.GetTextMapPropagator().Fields(), "traceparent", "trace context is still propagated")
+}
+
+func TestTracingSettingsAreValidated(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("MONGOURI", "mongodb://localhost:27017")
+	t.Setenv("MONGODB_DATABASE_NAME", "orgs")
+	t.Setenv("API_SECRET", "secret")
+	t.Setenv("TRACING_EXPORTER", "jaeger")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "otel-collector:4318")
+	t.Setenv("TRACING_SAMPLE_RATIO", "1.5")
+
+	_, err := config.Load()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "tracing.exporter (TRACING_EXPORTER) must be none, otlp or stdout")
+		assert.Contains(t, err.Error(), "tracing.otlp_endpoint (OTEL_EXPORTER_OTLP_ENDPOINT) must be an http or https URL")
+		assert.Contains(t, err.Error(), "tracing.sample_ratio (TRACING_SAMPLE_RATIO) must be between 0 and 1")
+	}
+}
+
+func TestRouterContinuesIncomingTrace(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, err := tracing.Setup(context.Background(), config.Default().Tracing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var traceID string
+	router := (&pkg.App{Config: config.Default()}).Router()
+	router.GET("/traced", func(c *gin.Context) {
+		traceID = trace.SpanContextFromContext(c.Request.Context()).TraceID().String()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/traced", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+}